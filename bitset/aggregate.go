@@ -0,0 +1,96 @@
+package bitset
+
+import "math/bits"
+
+// UnionAll returns a new bit set holding the bits set in any of sets. It makes a single
+// pass over the word positions without building intermediate sets.
+func UnionAll(sets ...*BitSet) *BitSet {
+	n := 0
+	for _, s := range sets {
+		if len(s.values) > n {
+			n = len(s.values)
+		}
+	}
+	result := &BitSet{values: make([]uint64, n)}
+	for i := range result.values {
+		var v uint64
+		for _, s := range sets {
+			if i < len(s.values) {
+				v |= s.values[i]
+			}
+		}
+		result.values[i] = v
+		result.onesCount += uint(bits.OnesCount64(v))
+	}
+	result.trim()
+	return result
+}
+
+// IntersectAll returns a new bit set holding the bits set in all of sets.
+// The result is empty if no set is given.
+func IntersectAll(sets ...*BitSet) *BitSet {
+	n := minLen(sets)
+	result := &BitSet{values: make([]uint64, n)}
+	for i := range result.values {
+		result.values[i] = intersectWord(sets, i)
+		result.onesCount += uint(bits.OnesCount64(result.values[i]))
+	}
+	result.trim()
+	return result
+}
+
+// UnionAllCardinality returns the cardinality of UnionAll(sets...) without allocating it.
+func UnionAllCardinality(sets ...*BitSet) uint {
+	n := 0
+	for _, s := range sets {
+		if len(s.values) > n {
+			n = len(s.values)
+		}
+	}
+	var count uint
+	for i := 0; i < n; i++ {
+		var v uint64
+		for _, s := range sets {
+			if i < len(s.values) {
+				v |= s.values[i]
+			}
+		}
+		count += uint(bits.OnesCount64(v))
+	}
+	return count
+}
+
+// IntersectAllCardinality returns the cardinality of IntersectAll(sets...) without allocating it.
+func IntersectAllCardinality(sets ...*BitSet) uint {
+	n := minLen(sets)
+	var count uint
+	for i := 0; i < n; i++ {
+		count += uint(bits.OnesCount64(intersectWord(sets, i)))
+	}
+	return count
+}
+
+func minLen(sets []*BitSet) int {
+	if len(sets) == 0 {
+		return 0
+	}
+	n := len(sets[0].values)
+	for _, s := range sets[1:] {
+		if len(s.values) < n {
+			n = len(s.values)
+		}
+	}
+	return n
+}
+
+// intersectWord returns the AND of the i-th word of sets, each of which must have it.
+func intersectWord(sets []*BitSet, i int) uint64 {
+	v := uint64(unitMask)
+	for _, s := range sets {
+		v &= s.values[i]
+		if v == 0 {
+			break
+		}
+	}
+	return v
+}
@@ -0,0 +1,89 @@
+package bitset
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	a := fromList(1, 2, 3, 100)
+	b := fromList(2, 3, 500)
+	c := fromList(3, 2, 7)
+
+	checkBits(t, "UnionAll", UnionAll(a, b, c), 1, 2, 3, 7, 100, 500)
+	checkBits(t, "IntersectAll", IntersectAll(a, b, c), 2, 3)
+	checkBits(t, "UnionAll()", UnionAll())
+	checkBits(t, "IntersectAll()", IntersectAll())
+	checkBits(t, "IntersectAll(a)", IntersectAll(a), 1, 2, 3, 100)
+	checkBits(t, "IntersectAll(a, b)", IntersectAll(a, fromList(100)), 100)
+	if n := UnionAllCardinality(a, b, c); n != 6 {
+		t.Errorf("UnionAllCardinality = %d, want 6", n)
+	}
+	if n := IntersectAllCardinality(a, b, c); n != 2 {
+		t.Errorf("IntersectAllCardinality = %d, want 2", n)
+	}
+	if n := IntersectAllCardinality(); n != 0 {
+		t.Errorf("IntersectAllCardinality() = %d, want 0", n)
+	}
+}
+
+func TestAggregateRandom(t *testing.T) {
+	sets := make([]*BitSet, 20)
+	for i := range sets {
+		sets[i] = New()
+		sets[i].SetRange(0, 2000)
+		for j := 0; j < 500; j++ {
+			sets[i].Clear(uint(rand.Intn(2000)))
+		}
+		sets[i].Set(uint(rand.Intn(100000)))
+	}
+	union, intersect := sets[0].Clone(), sets[0].Clone()
+	for _, s := range sets[1:] {
+		union.Or(s)
+		intersect.And(s)
+	}
+	if !UnionAll(sets...).Equal(union) || UnionAllCardinality(sets...) != union.Cardinality() {
+		t.Error("UnionAll differs from Or")
+	}
+	if !IntersectAll(sets...).Equal(intersect) || IntersectAllCardinality(sets...) != intersect.Cardinality() {
+		t.Error("IntersectAll differs from And")
+	}
+}
+
+func benchSets() []*BitSet {
+	sets := make([]*BitSet, 32)
+	for i := range sets {
+		sets[i] = New()
+		for j := 0; j < 10000; j++ {
+			sets[i].Set(uint(rand.Intn(N)))
+		}
+	}
+	return sets
+}
+
+func BenchmarkUnionAll(b *testing.B) {
+	sets := benchSets()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		UnionAll(sets...)
+	}
+}
+
+func BenchmarkUnionPairwise(b *testing.B) {
+	sets := benchSets()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		u := sets[0]
+		for _, s := range sets[1:] {
+			u = u.Union(s)
+		}
+	}
+}
+
+func BenchmarkUnionAllCardinality(b *testing.B) {
+	sets := benchSets()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		UnionAllCardinality(sets...)
+	}
+}
@@ -0,0 +1,104 @@
+package bitset
+
+import "sync/atomic"
+
+// AtomicBitSet is a fixed-size bit set that is safe for concurrent use by multiple
+// goroutines. Its words are never reallocated and every bit is updated with a
+// compare-and-swap, so Set, Clear and Get do not need a lock.
+type AtomicBitSet struct {
+	values    []uint64
+	onesCount atomic.Int64 // updated after the CAS, so it may lag behind the words
+}
+
+// NewAtomic returns a new AtomicBitSet that holds at least the specified number of bits.
+func NewAtomic(size uint) *AtomicBitSet {
+	n := size >> unitByteSize
+	if size&unitBitsMask != 0 {
+		n++
+	}
+	return &AtomicBitSet{
+		values: make([]uint64, n),
+	}
+}
+
+// Size return the number of bits the set can hold. Indexes must be less than it.
+func (b *AtomicBitSet) Size() uint64 {
+	return uint64(len(b.values)) << unitByteSize
+}
+
+// Set index to 1. It panics if index is out of range.
+func (b *AtomicBitSet) Set(index uint) {
+	b.TestAndSet(index)
+}
+
+// TestAndSet sets index to 1 and returns whether it was already 1.
+// It panics if index is out of range.
+func (b *AtomicBitSet) TestAndSet(index uint) bool {
+	addr := &b.values[index>>unitByteSize]
+	x := uint64(1) << (index & unitBitsMask)
+	for {
+		old := atomic.LoadUint64(addr)
+		if old&x != 0 {
+			return true
+		}
+		if atomic.CompareAndSwapUint64(addr, old, old|x) {
+			b.onesCount.Add(1)
+			return false
+		}
+	}
+}
+
+// Clear sets the bit specified by the index to 0. It panics if index is out of range.
+func (b *AtomicBitSet) Clear(index uint) {
+	b.TestAndClear(index)
+}
+
+// TestAndClear sets index to 0 and returns whether it was 1.
+// It panics if index is out of range.
+func (b *AtomicBitSet) TestAndClear(index uint) bool {
+	addr := &b.values[index>>unitByteSize]
+	x := uint64(1) << (index & unitBitsMask)
+	for {
+		old := atomic.LoadUint64(addr)
+		if old&x == 0 {
+			return false
+		}
+		if atomic.CompareAndSwapUint64(addr, old, old&^x) {
+			b.onesCount.Add(-1)
+			return true
+		}
+	}
+}
+
+// Get true if index is set 1, or return false. Indexes out of range are false.
+func (b *AtomicBitSet) Get(index uint) bool {
+	unitIndex := index >> unitByteSize
+	return unitIndex < uint(len(b.values)) &&
+		atomic.LoadUint64(&b.values[unitIndex])&(1<<(index&unitBitsMask)) != 0
+}
+
+// Cardinality returns the number of bits set to true. Under concurrent updates it
+// reflects the Set and Clear calls that have completed, and it is always in [0, Size].
+func (b *AtomicBitSet) Cardinality() uint {
+	n := b.onesCount.Load()
+	if n < 0 {
+		// a Clear counted before the Set of the same bit
+		return 0
+	}
+	if size := b.Size(); uint64(n) > size {
+		return uint(size)
+	}
+	return uint(n)
+}
+
+// BitSet returns a copy of the bits as a BitSet. Each word is read atomically, but
+// the copy is not a consistent snapshot if the bits are changing concurrently.
+func (b *AtomicBitSet) BitSet() *BitSet {
+	v := make([]uint64, len(b.values))
+	for i := range v {
+		v[i] = atomic.LoadUint64(&b.values[i])
+	}
+	s := &BitSet{}
+	s.load(v)
+	return s
+}
@@ -0,0 +1,128 @@
+package bitset
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAtomicBitSet(t *testing.T) {
+	b := NewAtomic(100)
+	if b.Size() != 128 {
+		t.Errorf("Size = %d, want 128", b.Size())
+	}
+	if b.TestAndSet(3) {
+		t.Error("TestAndSet(3) = true, want false")
+	}
+	if !b.TestAndSet(3) {
+		t.Error("TestAndSet(3) = false, want true")
+	}
+	b.Set(99)
+	if !b.Get(3) || !b.Get(99) || b.Get(4) || b.Get(1000) {
+		t.Error("Get")
+	}
+	if b.Cardinality() != 2 {
+		t.Errorf("Cardinality = %d, want 2", b.Cardinality())
+	}
+	if !b.TestAndClear(3) || b.TestAndClear(3) {
+		t.Error("TestAndClear(3)")
+	}
+	b.Clear(4)
+	checkBits(t, "BitSet", b.BitSet(), 99)
+}
+
+func TestAtomicBitSetConcurrent(t *testing.T) {
+	const size = 1 << 12
+	const workers = 8
+	b := NewAtomic(size)
+
+	var wg sync.WaitGroup
+	var counts [workers]int
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := uint(0); i < size; i++ {
+				if !b.TestAndSet(i) {
+					counts[w]++
+				}
+				b.Get((i + 1) % size)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	if total != size || b.Cardinality() != size {
+		t.Errorf("won %d bits, Cardinality = %d, want %d", total, b.Cardinality(), size)
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := uint(w); i < size; i += workers / 2 {
+				b.Clear(i)
+			}
+		}(w)
+	}
+	wg.Wait()
+	if b.Cardinality() != 0 {
+		t.Errorf("Cardinality = %d, want 0", b.Cardinality())
+	}
+}
+
+func TestAtomicBitSetSetClear(t *testing.T) {
+	const size = 128
+	const workers = 8
+	b := NewAtomic(size)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for n := 0; n < 20000; n++ {
+				i := uint(n+w) % size
+				if w%2 == 0 {
+					b.Set(i)
+				} else {
+					b.Clear(i)
+				}
+			}
+		}(w)
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for {
+		if c := b.Cardinality(); uint64(c) > b.Size() {
+			t.Fatalf("Cardinality = %d, more than Size %d", c, b.Size())
+		}
+		select {
+		case <-done:
+			if c, want := b.Cardinality(), b.BitSet().Cardinality(); c != want {
+				t.Errorf("Cardinality = %d, want %d", c, want)
+			}
+			return
+		default:
+		}
+	}
+}
+
+func BenchmarkAtomicSet(b *testing.B) {
+	s := NewAtomic(uint(N))
+	n := perm(N)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			s.Set(n[i%N])
+			i++
+		}
+	})
+}
@@ -15,6 +15,7 @@ const (
 type BitSet struct {
 	values    []uint64
 	onesCount uint
+	index     *rankIndex // built by Freeze, dropped by any mutation
 }
 
 // New Creates a new bit set. All bits are false
@@ -43,6 +44,32 @@ func (b *BitSet) Set(index uint) {
 	if b.values[unitIndex]&x == 0 {
 		b.values[unitIndex] |= x
 		b.onesCount++
+		b.index = nil
+	}
+}
+
+// TestAndSet sets index to 1 and returns whether it was already 1.
+func (b *BitSet) TestAndSet(index uint) bool {
+	if b.Get(index) {
+		return true
+	}
+	b.Set(index)
+	return false
+}
+
+// TestAndClear sets index to 0 and returns whether it was 1.
+func (b *BitSet) TestAndClear(index uint) bool {
+	if !b.Get(index) {
+		return false
+	}
+	b.Clear(index)
+	return true
+}
+
+// Flip sets index to the complement of its current value.
+func (b *BitSet) Flip(index uint) {
+	if !b.TestAndClear(index) {
+		b.Set(index)
 	}
 }
 
@@ -74,7 +101,13 @@ func (b *BitSet) Clear(index uint) {
 	}
 	b.values[unitIndex] = x
 	b.onesCount--
+	b.index = nil
+	b.trim()
+}
 
+// trim drops the trailing zero words. Words between len and cap of values
+// are always zero, so grow can reslice without clearing them.
+func (b *BitSet) trim() {
 	i := len(b.values) - 1
 	for ; i >= 0 && b.values[i] == 0; i-- {
 	}
@@ -88,6 +121,307 @@ func (b *BitSet) Reset() {
 	}
 	b.values = b.values[:0]
 	b.onesCount = 0
+	b.index = nil
+}
+
+// rangeUnits returns the first and last word touched by [fromIndex, toIndex) and the masks
+// of the bits in range within those two words. fromIndex must be less than toIndex.
+func rangeUnits(fromIndex, toIndex uint) (startUnit, endUnit int, firstMask, lastMask uint64) {
+	startUnit = int(fromIndex >> unitByteSize)
+	endUnit = int((toIndex - 1) >> unitByteSize)
+	firstMask = unitMask << (fromIndex & unitBitsMask)
+	lastMask = unitMask >> (unitBitsMask - (toIndex-1)&unitBitsMask)
+	return
+}
+
+// SetRange sets the bits from fromIndex (inclusive) to toIndex (exclusive) to 1.
+func (b *BitSet) SetRange(fromIndex, toIndex uint) {
+	if fromIndex >= toIndex {
+		return
+	}
+	startUnit, endUnit, firstMask, lastMask := rangeUnits(fromIndex, toIndex)
+	if endUnit >= len(b.values) {
+		b.grow(endUnit + 1)
+	}
+	b.index = nil
+	for i := startUnit; i <= endUnit; i++ {
+		mask := uint64(unitMask)
+		if i == startUnit {
+			mask &= firstMask
+		}
+		if i == endUnit {
+			mask &= lastMask
+		}
+		b.onesCount += uint(bits.OnesCount64(mask &^ b.values[i]))
+		b.values[i] |= mask
+	}
+}
+
+// ClearRange sets the bits from fromIndex (inclusive) to toIndex (exclusive) to 0.
+func (b *BitSet) ClearRange(fromIndex, toIndex uint) {
+	if fromIndex >= toIndex || fromIndex>>unitByteSize >= uint(len(b.values)) {
+		return
+	}
+	startUnit, endUnit, firstMask, lastMask := rangeUnits(fromIndex, toIndex)
+	if endUnit >= len(b.values) {
+		endUnit, lastMask = len(b.values)-1, unitMask
+	}
+	b.index = nil
+	for i := startUnit; i <= endUnit; i++ {
+		mask := uint64(unitMask)
+		if i == startUnit {
+			mask &= firstMask
+		}
+		if i == endUnit {
+			mask &= lastMask
+		}
+		b.onesCount -= uint(bits.OnesCount64(mask & b.values[i]))
+		b.values[i] &^= mask
+	}
+	b.trim()
+}
+
+// FlipRange sets each bit from fromIndex (inclusive) to toIndex (exclusive) to the
+// complement of its current value.
+func (b *BitSet) FlipRange(fromIndex, toIndex uint) {
+	if fromIndex >= toIndex {
+		return
+	}
+	startUnit, endUnit, firstMask, lastMask := rangeUnits(fromIndex, toIndex)
+	if endUnit >= len(b.values) {
+		b.grow(endUnit + 1)
+	}
+	b.index = nil
+	for i := startUnit; i <= endUnit; i++ {
+		mask := uint64(unitMask)
+		if i == startUnit {
+			mask &= firstMask
+		}
+		if i == endUnit {
+			mask &= lastMask
+		}
+		b.onesCount += uint(bits.OnesCount64(mask &^ b.values[i]))
+		b.onesCount -= uint(bits.OnesCount64(mask & b.values[i]))
+		b.values[i] ^= mask
+	}
+	b.trim()
+}
+
+// CountRange returns the number of bits set to true from fromIndex (inclusive) to
+// toIndex (exclusive).
+func (b BitSet) CountRange(fromIndex, toIndex uint) uint {
+	if fromIndex >= toIndex || fromIndex>>unitByteSize >= uint(len(b.values)) {
+		return 0
+	}
+	startUnit, endUnit, firstMask, lastMask := rangeUnits(fromIndex, toIndex)
+	if endUnit >= len(b.values) {
+		endUnit, lastMask = len(b.values)-1, unitMask
+	}
+	var n uint
+	for i := startUnit; i <= endUnit; i++ {
+		mask := uint64(unitMask)
+		if i == startUnit {
+			mask &= firstMask
+		}
+		if i == endUnit {
+			mask &= lastMask
+		}
+		n += uint(bits.OnesCount64(b.values[i] & mask))
+	}
+	return n
+}
+
+// And performs a logical AND of this bit set with the argument bit set.
+// Only the bits set in both remain set.
+func (b *BitSet) And(other *BitSet) {
+	b.index = nil
+	n := len(b.values)
+	if len(other.values) < n {
+		for i := len(other.values); i < n; i++ {
+			b.values[i] = 0
+		}
+		n = len(other.values)
+	}
+	var ones uint
+	for i := 0; i < n; i++ {
+		b.values[i] &= other.values[i]
+		ones += uint(bits.OnesCount64(b.values[i]))
+	}
+	b.values = b.values[:n]
+	b.onesCount = ones
+	b.trim()
+}
+
+// Or performs a logical OR of this bit set with the argument bit set.
+// The bits set in either one are set.
+func (b *BitSet) Or(other *BitSet) {
+	b.index = nil
+	if len(other.values) > len(b.values) {
+		b.grow(len(other.values))
+	}
+	for i, v := range other.values {
+		b.onesCount += uint(bits.OnesCount64(v &^ b.values[i]))
+		b.values[i] |= v
+	}
+	b.trim()
+}
+
+// Xor performs a logical XOR of this bit set with the argument bit set.
+// The bits set in exactly one of them are set.
+func (b *BitSet) Xor(other *BitSet) {
+	b.index = nil
+	if len(other.values) > len(b.values) {
+		b.grow(len(other.values))
+	}
+	for i, v := range other.values {
+		b.onesCount += uint(bits.OnesCount64(v &^ b.values[i]))
+		b.onesCount -= uint(bits.OnesCount64(v & b.values[i]))
+		b.values[i] ^= v
+	}
+	b.trim()
+}
+
+// AndNot clears all of the bits in this bit set whose corresponding bit is set
+// in the argument bit set.
+func (b *BitSet) AndNot(other *BitSet) {
+	b.index = nil
+	n := len(b.values)
+	if len(other.values) < n {
+		n = len(other.values)
+	}
+	for i := 0; i < n; i++ {
+		x := b.values[i] & other.values[i]
+		b.values[i] &^= x
+		b.onesCount -= uint(bits.OnesCount64(x))
+	}
+	b.trim()
+}
+
+// Intersection returns a new bit set holding the bits set in both b and other.
+func (b BitSet) Intersection(other *BitSet) *BitSet {
+	result := b.Clone()
+	result.And(other)
+	return result
+}
+
+// Union returns a new bit set holding the bits set in either b or other.
+func (b BitSet) Union(other *BitSet) *BitSet {
+	result := b.Clone()
+	result.Or(other)
+	return result
+}
+
+// SymmetricDifference returns a new bit set holding the bits set in exactly
+// one of b and other.
+func (b BitSet) SymmetricDifference(other *BitSet) *BitSet {
+	result := b.Clone()
+	result.Xor(other)
+	return result
+}
+
+// Difference returns a new bit set holding the bits set in b but not in other.
+func (b BitSet) Difference(other *BitSet) *BitSet {
+	result := b.Clone()
+	result.AndNot(other)
+	return result
+}
+
+// Not inverts every bit below upTo. The bits on or above upTo are not changed,
+// since the complement of a growing set has no end.
+func (b *BitSet) Not(upTo uint) {
+	b.FlipRange(0, upTo)
+}
+
+// Complement returns a new bit set holding the bits below upTo that are not set in b.
+func (b BitSet) Complement(upTo uint) *BitSet {
+	result := b.lowBits(upTo)
+	result.FlipRange(0, upTo)
+	return result
+}
+
+// Clone returns a deep copy of b.
+func (b BitSet) Clone() *BitSet {
+	v := make([]uint64, len(b.values))
+	copy(v, b.values)
+	return &BitSet{
+		values:    v,
+		onesCount: b.onesCount,
+	}
+}
+
+// CopyFrom makes b a copy of other, reusing the storage of b when it is large enough.
+func (b *BitSet) CopyFrom(other *BitSet) {
+	n := len(other.values)
+	if n > cap(b.values) {
+		b.values = make([]uint64, n)
+	} else {
+		for i := n; i < len(b.values); i++ {
+			b.values[i] = 0
+		}
+		b.values = b.values[:n]
+	}
+	copy(b.values, other.values)
+	b.onesCount = other.onesCount
+	b.index = nil
+}
+
+// Equal reports whether b and other have the same bits set. The unused trailing
+// space is ignored.
+func (b BitSet) Equal(other *BitSet) bool {
+	if b.onesCount != other.onesCount {
+		return false
+	}
+	x, y := b.values, other.values
+	if len(x) < len(y) {
+		x, y = y, x
+	}
+	for i, v := range y {
+		if x[i] != v {
+			return false
+		}
+	}
+	for _, v := range x[len(y):] {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSubsetOf reports whether every bit set in b is also set in other.
+func (b BitSet) IsSubsetOf(other *BitSet) bool {
+	if b.onesCount > other.onesCount {
+		return false
+	}
+	for i, v := range b.values {
+		if i >= len(other.values) {
+			if v != 0 {
+				return false
+			}
+		} else if v&^other.values[i] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSupersetOf reports whether every bit set in other is also set in b.
+func (b BitSet) IsSupersetOf(other *BitSet) bool {
+	return other.IsSubsetOf(&b)
+}
+
+// Intersects reports whether any bit is set in both b and other.
+func (b BitSet) Intersects(other *BitSet) bool {
+	n := len(b.values)
+	if len(other.values) < n {
+		n = len(other.values)
+	}
+	for i := 0; i < n; i++ {
+		if b.values[i]&other.values[i] != 0 {
+			return true
+		}
+	}
+	return false
 }
 
 // Cardinality returns the number of bits set to true.
@@ -103,6 +437,9 @@ func (b BitSet) Size() uint64 {
 // Length return the "logical size": the index of the highest set bit plus one.
 func (b BitSet) Length() int {
 	n := len(b.values)
+	for n > 0 && b.values[n-1] == 0 { // NewSize and grow may leave trailing zero words
+		n--
+	}
 	if n == 0 {
 		return 0
 	}
@@ -183,3 +520,89 @@ func (b BitSet) ForeachSetBit(fromIndex uint, do func(uint) bool) {
 		v = b.values[index]
 	}
 }
+
+// PrevSetBit returns the index of the nearest bit that is set to true that occurs on or before
+// the specified starting index. If no such bit exists then false is returned.
+// Use ForeachSetBitReverse for traverse.
+func (b BitSet) PrevSetBit(fromIndex uint) (uint, bool) {
+	index := fromIndex >> unitByteSize
+	valueLen := uint(len(b.values))
+	if valueLen == 0 {
+		return 0, false
+	}
+	var v uint64
+	if index >= valueLen {
+		index = valueLen - 1
+		v = b.values[index]
+	} else {
+		v = b.values[index] & (unitMask >> (unitBitsMask - fromIndex&unitBitsMask))
+	}
+	for {
+		if v != 0 {
+			return index<<unitByteSize + unitBitsMask - uint(bits.LeadingZeros64(v)), true
+		}
+		if index == 0 {
+			return 0, false
+		}
+		index--
+		v = b.values[index]
+	}
+}
+
+// PrevClearBit returns the index of the nearest bit that is set to false that occurs on or before
+// the specified starting index. If no such bit exists then false is returned.
+func (b BitSet) PrevClearBit(fromIndex uint) (uint, bool) {
+	index := fromIndex >> unitByteSize
+	if index >= uint(len(b.values)) {
+		return fromIndex, true
+	}
+	v := ^b.values[index] & (unitMask >> (unitBitsMask - fromIndex&unitBitsMask))
+	for {
+		if v != 0 {
+			return index<<unitByteSize + unitBitsMask - uint(bits.LeadingZeros64(v)), true // find the last bit that is set to 0
+		}
+		if index == 0 {
+			return 0, false
+		}
+		index--
+		v = ^b.values[index]
+	}
+}
+
+// ForeachSetBitReverse calls the do function for each bit that is set to true, from the
+// specified starting index down to 0.
+// param - do: return true to quit.
+func (b BitSet) ForeachSetBitReverse(fromIndex uint, do func(uint) bool) {
+	index := fromIndex >> unitByteSize
+	valueLen := uint(len(b.values))
+	if valueLen == 0 {
+		return
+	}
+	var v uint64
+	if index >= valueLen {
+		index = valueLen - 1
+		v = b.values[index]
+	} else {
+		v = b.values[index] & (unitMask >> (unitBitsMask - fromIndex&unitBitsMask))
+	}
+	for {
+		if v != 0 {
+			offset := index << unitByteSize
+			for {
+				i := unitBitsMask - uint(bits.LeadingZeros64(v))
+				if do(offset + i) { // if true break.
+					return
+				}
+				v &^= 1 << i
+				if v == 0 {
+					break
+				}
+			}
+		}
+		if index == 0 {
+			return
+		}
+		index--
+		v = b.values[index]
+	}
+}
@@ -1,6 +1,7 @@
 package bitset
 
 import (
+	"math/bits"
 	"math/rand"
 	"testing"
 )
@@ -81,6 +82,13 @@ func TestLength(t *testing.T) {
 	if b.Length() != 9 {
 		t.Error("Length")
 	}
+
+	// trailing zero words left by NewSize must not count
+	b = NewSize(1000)
+	b.Set(3)
+	if b.Length() != 4 {
+		t.Errorf("Length = %d, want 4", b.Length())
+	}
 }
 
 func TestCardinality(t *testing.T) {
@@ -186,6 +194,378 @@ func TestNextSetBit(t *testing.T) {
 	}
 }
 
+func fromList(l ...uint) *BitSet {
+	b := New()
+	for _, v := range l {
+		b.Set(v)
+	}
+	return b
+}
+
+func checkBits(t *testing.T, name string, b *BitSet, want ...uint) {
+	t.Helper()
+	var got []uint
+	b.ForeachSetBit(0, func(i uint) bool {
+		got = append(got, i)
+		return false
+	})
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", name, got, want)
+		return
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("%s = %v, want %v", name, got, want)
+			return
+		}
+	}
+	if b.Cardinality() != uint(len(want)) {
+		t.Errorf("%s Cardinality = %d, want %d", name, b.Cardinality(), len(want))
+	}
+	if n := len(b.values); n > 0 && b.values[n-1] == 0 {
+		t.Errorf("%s has trailing zero word", name)
+	}
+}
+
+// oracle is the reference set the BitSet is compared against.
+type oracle map[uint]bool
+
+func (o oracle) set(i uint, v bool) {
+	if v {
+		o[i] = true
+	} else {
+		delete(o, i)
+	}
+}
+
+func (o oracle) length() int {
+	n := 0
+	for i := range o {
+		if int(i) >= n {
+			n = int(i) + 1
+		}
+	}
+	return n
+}
+
+func (o oracle) indexes() []uint {
+	l := make([]uint, 0, len(o))
+	for i := range o {
+		l = append(l, i)
+	}
+	return l
+}
+
+// checkInvariants verifies the internal state of b and compares it with o.
+func checkInvariants(t *testing.T, step string, b *BitSet, o oracle) {
+	t.Helper()
+	var ones uint
+	for _, v := range b.values {
+		ones += uint(bits.OnesCount64(v))
+	}
+	if ones != b.onesCount {
+		t.Fatalf("%s: onesCount = %d, popcount = %d", step, b.onesCount, ones)
+	}
+	for i, v := range b.values[len(b.values):cap(b.values)] {
+		if v != 0 {
+			t.Fatalf("%s: word %d past len is %#x", step, len(b.values)+i, v)
+		}
+	}
+	if b.Cardinality() != uint(len(o)) {
+		t.Fatalf("%s: Cardinality = %d, want %d", step, b.Cardinality(), len(o))
+	}
+	if b.Length() != o.length() {
+		t.Fatalf("%s: Length = %d, want %d", step, b.Length(), o.length())
+	}
+	for i := range o {
+		if !b.Get(i) {
+			t.Fatalf("%s: Get(%d) = false", step, i)
+		}
+	}
+}
+
+// randomBitSet returns a set of n random bits below max and its oracle.
+func randomBitSet(n, max int) (*BitSet, oracle) {
+	b := New()
+	o := oracle{}
+	for i := 0; i < n; i++ {
+		v := uint(rand.Intn(max))
+		b.Set(v)
+		o[v] = true
+	}
+	return b, o
+}
+
+func TestSetOperations(t *testing.T) {
+	a := fromList(1, 2, 64, 200)
+	b := fromList(2, 3, 64, 130)
+
+	checkBits(t, "Intersection", a.Intersection(b), 2, 64)
+	checkBits(t, "Union", a.Union(b), 1, 2, 3, 64, 130, 200)
+	checkBits(t, "SymmetricDifference", a.SymmetricDifference(b), 1, 3, 130, 200)
+	checkBits(t, "Difference", a.Difference(b), 1, 200)
+	checkBits(t, "Difference", b.Difference(a), 3, 130)
+	checkBits(t, "a", a, 1, 2, 64, 200)
+
+	c := fromList(1, 200)
+	c.And(fromList(1))
+	checkBits(t, "And", c, 1)
+	if c.Size() != unitBitsNum {
+		t.Errorf("Size = %d, want %d", c.Size(), unitBitsNum)
+	}
+	c.Set(300)
+	checkBits(t, "And then Set", c, 1, 300)
+
+	d := fromList(5, 300)
+	d.Xor(fromList(300))
+	checkBits(t, "Xor", d, 5)
+	d.AndNot(fromList(5, 1000))
+	checkBits(t, "AndNot", d)
+	d.Or(fromList(7, 500))
+	checkBits(t, "Or", d, 7, 500)
+	f := New()
+	f.Set(1)
+	f.Or(NewSize(100000))
+	checkBits(t, "Or empty", f, 1)
+	checkBits(t, "Union empty", fromList(1).Union(NewSize(100000)), 1)
+
+	e := fromList(10, 20)
+	e.And(e)
+	checkBits(t, "And self", e, 10, 20)
+	e.Xor(e)
+	checkBits(t, "Xor self", e)
+}
+
+func TestRange(t *testing.T) {
+	b := New()
+	b.SetRange(3, 3)
+	if b.Cardinality() != 0 {
+		t.Errorf("SetRange(3, 3) Cardinality = %d, want 0", b.Cardinality())
+	}
+	b.SetRange(60, 70)
+	checkBits(t, "SetRange", b, 60, 61, 62, 63, 64, 65, 66, 67, 68, 69)
+	if b.Length() != 70 {
+		t.Errorf("Length = %d, want 70", b.Length())
+	}
+	b.ClearRange(62, 68)
+	checkBits(t, "ClearRange", b, 60, 61, 68, 69)
+	b.ClearRange(64, 1000)
+	checkBits(t, "ClearRange tail", b, 60, 61)
+	if b.Size() != unitBitsNum {
+		t.Errorf("Size = %d, want %d", b.Size(), unitBitsNum)
+	}
+	b.FlipRange(61, 64)
+	checkBits(t, "FlipRange", b, 60, 62, 63)
+	b.FlipRange(0, 256)
+	if b.Cardinality() != 253 || b.Length() != 256 {
+		t.Errorf("FlipRange Cardinality = %d, Length = %d", b.Cardinality(), b.Length())
+	}
+	b.FlipRange(0, 256)
+	checkBits(t, "FlipRange twice", b, 60, 62, 63)
+
+	for i := 0; i < 1000; i++ {
+		from := uint(rand.Intn(500))
+		to := uint(rand.Intn(500))
+		want := New()
+		for j := uint(0); j < 500; j++ {
+			if b.Get(j) {
+				want.Set(j)
+			}
+		}
+		var count uint
+		for j := from; j < to; j++ {
+			if b.Get(j) {
+				count++
+			}
+		}
+		if c := b.CountRange(from, to); c != count {
+			t.Fatalf("CountRange(%d, %d) = %d, want %d", from, to, c, count)
+		}
+		for j := from; j < to; j++ {
+			switch i % 3 {
+			case 0:
+				want.Set(j)
+			case 1:
+				want.Clear(j)
+			case 2:
+				if want.Get(j) {
+					want.Clear(j)
+				} else {
+					want.Set(j)
+				}
+			}
+		}
+		switch i % 3 {
+		case 0:
+			b.SetRange(from, to)
+		case 1:
+			b.ClearRange(from, to)
+		case 2:
+			b.FlipRange(from, to)
+		}
+		if b.Cardinality() != want.Cardinality() || b.Length() != want.Length() {
+			t.Fatalf("%d: Cardinality = %d, Length = %d, want %d, %d", i,
+				b.Cardinality(), b.Length(), want.Cardinality(), want.Length())
+		}
+		for j := uint(0); j < 500; j++ {
+			if b.Get(j) != want.Get(j) {
+				t.Fatalf("%d: Get(%d) = %v", i, j, b.Get(j))
+			}
+		}
+	}
+}
+
+func TestPrevSetBit(t *testing.T) {
+	bs := New()
+	if _, ok := bs.PrevSetBit(100); ok {
+		t.Errorf("PrevSetBit(100) = %v, want false", ok)
+	}
+	bs.Set(1)
+	bs.Set(2)
+	bs.Set(unitBitsNum + 3)
+
+	tests := []struct {
+		from uint
+		want uint
+		ok   bool
+	}{
+		{0, 0, false},
+		{1, 1, true},
+		{2, 2, true},
+		{unitBitsNum + 2, 2, true},
+		{unitBitsNum + 3, unitBitsNum + 3, true},
+		{10000, unitBitsNum + 3, true},
+	}
+	for _, tt := range tests {
+		if i, ok := bs.PrevSetBit(tt.from); i != tt.want || ok != tt.ok {
+			t.Errorf("PrevSetBit(%d) = %d, %v, want %d, %v", tt.from, i, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPrevClearBit(t *testing.T) {
+	bs := New()
+	bs.SetRange(0, unitBitsNum+2)
+	bs.Clear(5)
+
+	tests := []struct {
+		from uint
+		want uint
+		ok   bool
+	}{
+		{0, 0, false},
+		{4, 0, false},
+		{5, 5, true},
+		{unitBitsNum + 1, 5, true},
+		{unitBitsNum + 2, unitBitsNum + 2, true},
+		{10000, 10000, true},
+	}
+	for _, tt := range tests {
+		if i, ok := bs.PrevClearBit(tt.from); i != tt.want || ok != tt.ok {
+			t.Errorf("PrevClearBit(%d) = %d, %v, want %d, %v", tt.from, i, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestForeachSetBitReverse(t *testing.T) {
+	bs := fromList(0, 3, 63, 64, 200)
+	var got []uint
+	bs.ForeachSetBitReverse(1000, func(i uint) bool {
+		got = append(got, i)
+		return false
+	})
+	want := []uint{200, 64, 63, 3, 0}
+	if len(got) != len(want) {
+		t.Fatalf("ForeachSetBitReverse = %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("ForeachSetBitReverse = %v, want %v", got, want)
+		}
+	}
+
+	got = got[:0]
+	bs.ForeachSetBitReverse(63, func(i uint) bool {
+		got = append(got, i)
+		return i == 3
+	})
+	if len(got) != 2 || got[0] != 63 || got[1] != 3 {
+		t.Errorf("ForeachSetBitReverse(63) = %v, want [63 3]", got)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a := fromList(1, 64, 200)
+	b := NewSize(10000)
+	b.Set(1)
+	b.Set(64)
+	b.Set(200)
+	if !a.Equal(b) || !b.Equal(a) {
+		t.Error("Equal should ignore trailing space")
+	}
+	c := a.Clone()
+	c.Set(5)
+	if a.Equal(c) || a.Get(5) {
+		t.Error("Clone should be deep")
+	}
+	if !a.IsSubsetOf(c) || c.IsSubsetOf(a) || !c.IsSupersetOf(a) || a.IsSupersetOf(c) {
+		t.Error("IsSubsetOf")
+	}
+	if !a.IsSubsetOf(b) || !a.IsSupersetOf(b) {
+		t.Error("equal sets are subset and superset of each other")
+	}
+	if !New().IsSubsetOf(a) || New().Intersects(a) {
+		t.Error("empty set")
+	}
+	if !a.Intersects(fromList(0, 200)) || a.Intersects(fromList(0, 201, 5000)) {
+		t.Error("Intersects")
+	}
+	d := fromList(1, 64, 201)
+	if a.Equal(d) || a.IsSubsetOf(d) {
+		t.Error("Equal with the same cardinality")
+	}
+
+	e := fromList(1000)
+	storage := &e.values[0]
+	e.CopyFrom(a)
+	if !e.Equal(a) || &e.values[0] != storage {
+		t.Error("CopyFrom should reuse storage")
+	}
+	e.Set(900)
+	checkBits(t, "CopyFrom", e, 1, 64, 200, 900)
+	e.CopyFrom(NewSize(100000))
+	if e.Cardinality() != 0 || e.Get(900) {
+		t.Error("CopyFrom empty")
+	}
+}
+
+func TestFlip(t *testing.T) {
+	b := New()
+	if b.TestAndSet(70) || !b.TestAndSet(70) {
+		t.Error("TestAndSet(70)")
+	}
+	if !b.TestAndClear(70) || b.TestAndClear(70) || b.TestAndClear(1000) {
+		t.Error("TestAndClear(70)")
+	}
+	b.Flip(3)
+	b.Flip(200)
+	b.Flip(200)
+	checkBits(t, "Flip", b, 3)
+
+	b = fromList(1, 3, 100)
+	b.Not(4)
+	checkBits(t, "Not", b, 0, 2, 100)
+	b.Not(0)
+	checkBits(t, "Not(0)", b, 0, 2, 100)
+
+	c := b.Complement(5)
+	checkBits(t, "Complement", c, 1, 3, 4)
+	checkBits(t, "Complement source", b, 0, 2, 100)
+	if c := New().Complement(130); c.Cardinality() != 130 || c.Length() != 130 {
+		t.Errorf("Complement Cardinality = %d, Length = %d", c.Cardinality(), c.Length())
+	}
+}
+
 var N = 1000000
 
 func newBitSet() *BitSet {
@@ -233,6 +613,13 @@ func BenchmarkClear(b *testing.B) {
 	}
 }
 
+func BenchmarkSetRange(b *testing.B) {
+	s := New()
+	for i := 0; i < b.N; i++ {
+		s.SetRange(uint(i%N), uint(i%N)+4096)
+	}
+}
+
 func BenchmarkNextClearBit(b *testing.B) {
 	s := newBitSet()
 	n := perm(N)
@@ -251,6 +638,15 @@ func BenchmarkNextSetBit(b *testing.B) {
 	}
 }
 
+func BenchmarkPrevSetBit(b *testing.B) {
+	s := newBitSet()
+	n := perm(N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.PrevSetBit(n[i%N])
+	}
+}
+
 func BenchmarkForeachSetBit(b *testing.B) {
 	b.StopTimer()
 	s := NewSize(10000)
@@ -0,0 +1,83 @@
+package bitset
+
+import "math/bits"
+
+// SetMany sets each of the indexes to 1. It grows the storage at most once.
+func (b *BitSet) SetMany(indexes []uint) {
+	var max uint
+	for _, i := range indexes {
+		if i > max {
+			max = i
+		}
+	}
+	if len(indexes) == 0 {
+		return
+	}
+	if unitIndex := int(max >> unitByteSize); unitIndex >= len(b.values) {
+		b.grow(unitIndex + 1)
+	}
+	values := b.values
+	var n uint
+	for _, i := range indexes {
+		w := &values[i>>unitByteSize]
+		x := uint64(1) << (i & unitBitsMask)
+		if *w&x == 0 {
+			*w |= x
+			n++
+		}
+	}
+	if n != 0 {
+		b.onesCount += n
+		b.index = nil
+	}
+}
+
+// ClearMany sets each of the indexes to 0.
+func (b *BitSet) ClearMany(indexes []uint) {
+	values := b.values
+	var n uint
+	for _, i := range indexes {
+		unitIndex := i >> unitByteSize
+		if unitIndex >= uint(len(values)) {
+			continue
+		}
+		x := uint64(1) << (i & unitBitsMask)
+		if values[unitIndex]&x != 0 {
+			values[unitIndex] &^= x
+			n++
+		}
+	}
+	if n != 0 {
+		b.onesCount -= n
+		b.index = nil
+		b.trim()
+	}
+}
+
+// GetMany stores in out[k] whether indexes[k] is set 1.
+// It panics if out is shorter than indexes.
+func (b BitSet) GetMany(indexes []uint, out []bool) {
+	out = out[:len(indexes)]
+	values := b.values
+	for k, i := range indexes {
+		unitIndex := i >> unitByteSize
+		out[k] = unitIndex < uint(len(values)) && values[unitIndex]&(1<<(i&unitBitsMask)) != 0
+	}
+}
+
+// popcount returns the number of bits set in words. The loop is unrolled so the
+// compiler can keep four POPCNT instructions in flight.
+func popcount(words []uint64) uint {
+	var n0, n1, n2, n3 int
+	for len(words) >= 4 {
+		n0 += bits.OnesCount64(words[0])
+		n1 += bits.OnesCount64(words[1])
+		n2 += bits.OnesCount64(words[2])
+		n3 += bits.OnesCount64(words[3])
+		words = words[4:]
+	}
+	for _, v := range words {
+		n0 += bits.OnesCount64(v)
+	}
+	return uint(n0 + n1 + n2 + n3)
+}
@@ -0,0 +1,136 @@
+package bitset
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSetMany(t *testing.T) {
+	b := fromList(5)
+	b.SetMany([]uint{5, 1000, 3, 1000, 64})
+	checkBits(t, "SetMany", b, 3, 5, 64, 1000)
+	b.SetMany(nil)
+	checkBits(t, "SetMany nil", b, 3, 5, 64, 1000)
+
+	out := make([]bool, 5)
+	b.GetMany([]uint{3, 4, 1000, 100000, 64}, out)
+	want := []bool{true, false, true, false, true}
+	for i := range out {
+		if out[i] != want[i] {
+			t.Errorf("GetMany = %v, want %v", out, want)
+			break
+		}
+	}
+
+	b.ClearMany([]uint{1000, 1000, 100000, 5})
+	checkBits(t, "ClearMany", b, 3, 64)
+}
+
+func TestSetManyRandom(t *testing.T) {
+	indexes := make([]uint, 5000)
+	for i := range indexes {
+		indexes[i] = uint(rand.Intn(100000))
+	}
+	b, want := New(), New()
+	b.SetMany(indexes)
+	for _, i := range indexes {
+		want.Set(i)
+	}
+	if !b.Equal(want) || b.Length() != want.Length() {
+		t.Fatal("SetMany differs from Set")
+	}
+	b.ClearMany(indexes[:2500])
+	for _, i := range indexes[:2500] {
+		want.Clear(i)
+	}
+	if !b.Equal(want) {
+		t.Fatal("ClearMany differs from Clear")
+	}
+}
+
+func benchIndexes() []uint {
+	n := perm(N)
+	return n[:100000]
+}
+
+func BenchmarkSetMany(b *testing.B) {
+	n := benchIndexes()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := New()
+		s.SetMany(n)
+	}
+}
+
+func BenchmarkSetLoop(b *testing.B) {
+	n := benchIndexes()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s := New()
+		for _, v := range n {
+			s.Set(v)
+		}
+	}
+}
+
+func BenchmarkGetMany(b *testing.B) {
+	s := newBitSet()
+	n := benchIndexes()
+	out := make([]bool, len(n))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.GetMany(n, out)
+	}
+}
+
+func BenchmarkGetLoop(b *testing.B) {
+	s := newBitSet()
+	n := benchIndexes()
+	out := make([]bool, len(n))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for k, v := range n {
+			out[k] = s.Get(v)
+		}
+	}
+}
+
+func BenchmarkClearMany(b *testing.B) {
+	n := benchIndexes()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		s := newBitSet()
+		b.StartTimer()
+		s.ClearMany(n)
+	}
+}
+
+func BenchmarkClearLoop(b *testing.B) {
+	n := benchIndexes()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		s := newBitSet()
+		b.StartTimer()
+		for _, v := range n {
+			s.Clear(v)
+		}
+	}
+}
+
+func TestPopcount(t *testing.T) {
+	for n := 0; n < 10; n++ {
+		words := make([]uint64, n)
+		var want uint
+		for i := range words {
+			words[i] = rand.Uint64()
+			for v := words[i]; v != 0; v &= v - 1 {
+				want++
+			}
+		}
+		if c := popcount(words); c != want {
+			t.Errorf("popcount(%d words) = %d, want %d", n, c, want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package bitset
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// The binary format is a one byte version, the number of words as a
+// little-endian uint64, then each word as a little-endian uint64.
+// Trailing zero words are never written.
+const (
+	binaryVersion    = 1
+	binaryHeaderSize = 1 + 8
+	wordByteSize     = 8
+
+	// readChunkWords bounds the memory allocated ahead of the data actually read,
+	// so a corrupt word count can not make ReadFrom allocate a huge slice.
+	readChunkWords = 1 << 13
+)
+
+var (
+	// ErrUnsupportedVersion is returned when decoding data written in an unknown format version.
+	ErrUnsupportedVersion = errors.New("bitset: unsupported encoding version")
+	// ErrTruncated is returned when the data ends before the encoded bit set does.
+	ErrTruncated = errors.New("bitset: truncated data")
+	// ErrCorrupt is returned when the data is not a valid encoded bit set.
+	ErrCorrupt = errors.New("bitset: corrupt data")
+)
+
+func (b BitSet) usedWords() []uint64 {
+	i := len(b.values) - 1
+	for ; i >= 0 && b.values[i] == 0; i-- {
+	}
+	return b.values[:i+1]
+}
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface.
+func (b BitSet) MarshalBinary() ([]byte, error) {
+	words := b.usedWords()
+	data := make([]byte, binaryHeaderSize+len(words)*wordByteSize)
+	data[0] = binaryVersion
+	binary.LittleEndian.PutUint64(data[1:], uint64(len(words)))
+	p := data[binaryHeaderSize:]
+	for i, v := range words {
+		binary.LittleEndian.PutUint64(p[i*wordByteSize:], v)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface.
+// The content of b is replaced by the decoded bit set.
+func (b *BitSet) UnmarshalBinary(data []byte) error {
+	n, err := decodeHeader(data)
+	if err != nil {
+		return err
+	}
+	data = data[binaryHeaderSize:]
+	if uint64(len(data))/wordByteSize < n {
+		return ErrTruncated
+	}
+	if uint64(len(data)) != n*wordByteSize {
+		return ErrCorrupt
+	}
+	values := make([]uint64, n)
+	for i := range values {
+		values[i] = binary.LittleEndian.Uint64(data[i*wordByteSize:])
+	}
+	b.load(values)
+	return nil
+}
+
+// WriteTo implements the io.WriterTo interface. It writes the same format as MarshalBinary.
+func (b BitSet) WriteTo(w io.Writer) (int64, error) {
+	words := b.usedWords()
+	var header [binaryHeaderSize]byte
+	header[0] = binaryVersion
+	binary.LittleEndian.PutUint64(header[1:], uint64(len(words)))
+	n, err := w.Write(header[:])
+	written := int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	var buf [512 * wordByteSize]byte
+	for len(words) > 0 {
+		k := len(buf) / wordByteSize
+		if k > len(words) {
+			k = len(words)
+		}
+		for i, v := range words[:k] {
+			binary.LittleEndian.PutUint64(buf[i*wordByteSize:], v)
+		}
+		n, err = w.Write(buf[:k*wordByteSize])
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+		words = words[k:]
+	}
+	return written, nil
+}
+
+// ReadFrom implements the io.ReaderFrom interface. It reads data in the format
+// written by WriteTo and replaces the content of b.
+func (b *BitSet) ReadFrom(r io.Reader) (int64, error) {
+	var header [binaryHeaderSize]byte
+	n, err := io.ReadFull(r, header[:])
+	read := int64(n)
+	if err != nil {
+		return read, readError(err)
+	}
+	count, err := decodeHeader(header[:])
+	if err != nil {
+		return read, err
+	}
+
+	var values []uint64
+	var buf [readChunkWords * wordByteSize]byte
+	for remain := count; remain > 0; {
+		k := uint64(readChunkWords)
+		if k > remain {
+			k = remain
+		}
+		n, err = io.ReadFull(r, buf[:k*wordByteSize])
+		read += int64(n)
+		if err != nil {
+			return read, readError(err)
+		}
+		for i := uint64(0); i < k; i++ {
+			values = append(values, binary.LittleEndian.Uint64(buf[i*wordByteSize:]))
+		}
+		remain -= k
+	}
+	b.load(values)
+	return read, nil
+}
+
+func decodeHeader(data []byte) (uint64, error) {
+	if len(data) < binaryHeaderSize {
+		return 0, ErrTruncated
+	}
+	if data[0] != binaryVersion {
+		return 0, ErrUnsupportedVersion
+	}
+	n := binary.LittleEndian.Uint64(data[1:])
+	if n > uint64(maxInt)/wordByteSize {
+		return 0, ErrCorrupt
+	}
+	return n, nil
+}
+
+func readError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrTruncated
+	}
+	return err
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+// load replaces the content of b with values and rebuilds the cached count.
+func (b *BitSet) load(values []uint64) {
+	b.values = values
+	b.onesCount = popcount(values)
+	b.index = nil
+	b.trim()
+}
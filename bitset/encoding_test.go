@@ -0,0 +1,110 @@
+package bitset
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"io"
+	"testing"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*BitSet)(nil)
+	_ encoding.BinaryUnmarshaler = (*BitSet)(nil)
+	_ io.WriterTo                = (*BitSet)(nil)
+	_ io.ReaderFrom              = (*BitSet)(nil)
+)
+
+func TestMarshalBinary(t *testing.T) {
+	b := NewSize(1000)
+	for _, v := range []uint{0, 1, 63, 64, 500, 999} {
+		b.Set(v)
+	}
+	data, err := b.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != binaryHeaderSize+16*wordByteSize {
+		t.Errorf("len(data) = %d, want %d", len(data), binaryHeaderSize+16*wordByteSize)
+	}
+
+	var c BitSet
+	if err := c.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	checkBits(t, "UnmarshalBinary", &c, 0, 1, 63, 64, 500, 999)
+
+	var e BitSet
+	data, _ = e.MarshalBinary()
+	c.Set(7)
+	if err := c.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	checkBits(t, "UnmarshalBinary empty", &c)
+}
+
+func TestWriteTo(t *testing.T) {
+	b := New()
+	for i := uint(0); i < 100000; i += 7 {
+		b.Set(i)
+	}
+	var buf bytes.Buffer
+	n, err := b.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo = %d, want %d", n, buf.Len())
+	}
+	data, _ := b.MarshalBinary()
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Error("WriteTo and MarshalBinary differ")
+	}
+
+	c := New()
+	m, err := c.ReadFrom(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != n {
+		t.Errorf("ReadFrom = %d, want %d", m, n)
+	}
+	if c.Cardinality() != b.Cardinality() || c.Length() != b.Length() {
+		t.Errorf("ReadFrom Cardinality = %d, Length = %d", c.Cardinality(), c.Length())
+	}
+	for i := uint(0); i < 100000; i++ {
+		if c.Get(i) != b.Get(i) {
+			t.Fatalf("Get(%d) = %v", i, c.Get(i))
+		}
+	}
+}
+
+func TestUnmarshalBinaryError(t *testing.T) {
+	data, _ := fromList(1, 100).MarshalBinary()
+
+	var b BitSet
+	tests := []struct {
+		data []byte
+		err  error
+	}{
+		{nil, ErrTruncated},
+		{data[:5], ErrTruncated},
+		{data[:len(data)-1], ErrTruncated},
+		{append([]byte{2}, data[1:]...), ErrUnsupportedVersion},
+		{append(data[:len(data):len(data)], 0), ErrCorrupt},
+		{[]byte{1, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, ErrCorrupt},
+	}
+	for i, tt := range tests {
+		if err := b.UnmarshalBinary(tt.data); !errors.Is(err, tt.err) {
+			t.Errorf("%d: UnmarshalBinary = %v, want %v", i, err, tt.err)
+		}
+		if _, err := b.ReadFrom(bytes.NewReader(tt.data)); tt.err != ErrCorrupt && !errors.Is(err, tt.err) {
+			t.Errorf("%d: ReadFrom = %v, want %v", i, err, tt.err)
+		}
+	}
+
+	huge := []byte{1, 0, 0, 0, 8, 0, 0, 0, 0} // 1<<27 words, valid on 32-bit too
+	if _, err := b.ReadFrom(bytes.NewReader(huge)); err != ErrTruncated {
+		t.Errorf("ReadFrom = %v, want %v", err, ErrTruncated)
+	}
+}
@@ -0,0 +1,122 @@
+package bitset
+
+import "errors"
+
+// ErrOutOfRange is returned when an index is not less than the width of a FixedBitSet.
+var ErrOutOfRange = errors.New("bitset: index out of range")
+
+// Resize sets the storage of b to hold exactly size bits, so that setting any index
+// below size does not reallocate. The bits on or above size are cleared.
+func (b *BitSet) Resize(size uint) {
+	b.ClearRange(size, ^uint(0))
+	n := int(size >> unitByteSize)
+	if size&unitBitsMask != 0 {
+		n++
+	}
+	if n == cap(b.values) {
+		return
+	}
+	v := make([]uint64, len(b.values), n)
+	copy(v, b.values)
+	b.values = v
+}
+
+// Shrink is like Resize, but it never grows the storage.
+func (b *BitSet) Shrink(size uint) {
+	if size >= uint(cap(b.values))<<unitByteSize {
+		b.ClearRange(size, ^uint(0))
+		return
+	}
+	b.Resize(size)
+}
+
+// Compact drops the trailing zero words and releases the storage that is not
+// needed by the bits set to true.
+func (b *BitSet) Compact() {
+	b.trim()
+	b.Resize(uint(len(b.values)) << unitByteSize)
+}
+
+// FixedBitSet is a bit set with a declared width. Its storage is allocated once by
+// NewFixed or Resize, and setting an index past the width fails instead of growing.
+type FixedBitSet struct {
+	set   BitSet
+	width uint
+}
+
+// NewFixed returns a new FixedBitSet that holds the bits below width.
+func NewFixed(width uint) *FixedBitSet {
+	f := &FixedBitSet{width: width}
+	f.set.Resize(width)
+	return f
+}
+
+// Width returns the number of bits the set can hold. Indexes must be less than it.
+func (f *FixedBitSet) Width() uint {
+	return f.width
+}
+
+// Resize changes the width of the set, reallocating its storage once.
+// The bits on or above the new width are cleared.
+func (f *FixedBitSet) Resize(width uint) {
+	f.set.Resize(width)
+	f.width = width
+}
+
+// Set index to 1. It returns ErrOutOfRange if index is not less than Width.
+func (f *FixedBitSet) Set(index uint) error {
+	if index >= f.width {
+		return ErrOutOfRange
+	}
+	f.set.Set(index)
+	return nil
+}
+
+// Clear sets the bit specified by the index to 0.
+// It returns ErrOutOfRange if index is not less than Width.
+func (f *FixedBitSet) Clear(index uint) error {
+	if index >= f.width {
+		return ErrOutOfRange
+	}
+	f.set.Clear(index)
+	return nil
+}
+
+// Get true if index is set 1, or return false.
+func (f *FixedBitSet) Get(index uint) bool {
+	return f.set.Get(index)
+}
+
+// Reset all bits to 0. The storage is kept.
+func (f *FixedBitSet) Reset() {
+	f.set.Reset()
+}
+
+// Cardinality returns the number of bits set to true.
+func (f *FixedBitSet) Cardinality() uint {
+	return f.set.Cardinality()
+}
+
+// NextSetBit returns the index of the first bit that is set to true that occurs on or after
+// the specified starting index. If no such bit exists then false is returned.
+func (f *FixedBitSet) NextSetBit(fromIndex uint) (uint, bool) {
+	return f.set.NextSetBit(fromIndex)
+}
+
+// NextClearBit returns the index of the first bit that is set to false that occurs on or after
+// the specified starting index. If no such bit exists below Width then false is returned.
+func (f *FixedBitSet) NextClearBit(fromIndex uint) (uint, bool) {
+	i := f.set.NextClearBit(fromIndex)
+	return i, i < f.width
+}
+
+// ForeachSetBit calls the do function for each bit that is set to true.
+// param - do: return true to quit.
+func (f *FixedBitSet) ForeachSetBit(fromIndex uint, do func(uint) bool) {
+	f.set.ForeachSetBit(fromIndex, do)
+}
+
+// BitSet returns a copy of the bits as a BitSet.
+func (f *FixedBitSet) BitSet() *BitSet {
+	return f.set.Clone()
+}
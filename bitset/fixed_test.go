@@ -0,0 +1,99 @@
+package bitset
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResize(t *testing.T) {
+	b := fromList(1, 100, 1000)
+	b.Resize(10000)
+	if cap(b.values) != 157 {
+		t.Errorf("cap = %d, want 157", cap(b.values))
+	}
+	storage := &b.values[0]
+	for i := uint(0); i < 10000; i += 3 {
+		b.Set(i)
+		b.Clear(i)
+	}
+	b.Set(9999)
+	if &b.values[0] != storage {
+		t.Error("Set below the size should not reallocate")
+	}
+	checkBits(t, "Resize grow", b, 1, 100, 1000, 9999)
+
+	b.Resize(101)
+	checkBits(t, "Resize shrink", b, 1, 100)
+	if cap(b.values) != 2 {
+		t.Errorf("cap = %d, want 2", cap(b.values))
+	}
+
+	b.Shrink(1000)
+	if cap(b.values) != 2 {
+		t.Errorf("Shrink should not grow, cap = %d", cap(b.values))
+	}
+	b.Shrink(64)
+	checkBits(t, "Shrink", b, 1)
+
+	c := NewSize(100000)
+	c.Set(5)
+	c.Compact()
+	if cap(c.values) != 1 || len(c.values) != 1 {
+		t.Errorf("Compact len = %d, cap = %d, want 1", len(c.values), cap(c.values))
+	}
+	c.Clear(5)
+	c.Compact()
+	if cap(c.values) != 0 || c.Length() != 0 {
+		t.Errorf("Compact cap = %d, Length = %d", cap(c.values), c.Length())
+	}
+}
+
+func TestFixedBitSet(t *testing.T) {
+	f := NewFixed(100)
+	if f.Width() != 100 {
+		t.Errorf("Width = %d, want 100", f.Width())
+	}
+	if err := f.Set(99); err != nil {
+		t.Error(err)
+	}
+	if err := f.Set(100); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Set(100) = %v, want %v", err, ErrOutOfRange)
+	}
+	if err := f.Clear(100); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Clear(100) = %v, want %v", err, ErrOutOfRange)
+	}
+	if f.Get(100) || !f.Get(99) || f.Cardinality() != 1 {
+		t.Error("Get")
+	}
+
+	storage := &f.set.values[:1][0]
+	for i := uint(0); i < 100; i++ {
+		f.Set(i)
+	}
+	if &f.set.values[0] != storage {
+		t.Error("Set should not reallocate")
+	}
+	if _, ok := f.NextClearBit(0); ok {
+		t.Error("NextClearBit of a full set should fail")
+	}
+	for i := uint(0); i < 100; i++ {
+		f.Clear(i)
+	}
+	if i, ok := f.NextClearBit(0); !ok || i != 0 {
+		t.Errorf("NextClearBit(0) = %d, %v", i, ok)
+	}
+
+	f.Set(50)
+	f.Resize(50)
+	if err := f.Set(50); err == nil || f.Cardinality() != 0 {
+		t.Error("Resize should clear and bound bit 50")
+	}
+	f.Resize(200)
+	if err := f.Set(150); err != nil {
+		t.Error(err)
+	}
+	if i, ok := f.NextSetBit(0); !ok || i != 150 {
+		t.Errorf("NextSetBit(0) = %d, %v", i, ok)
+	}
+	checkBits(t, "BitSet", f.BitSet(), 150)
+}
@@ -0,0 +1,97 @@
+package bitset
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// maxStringBits is the number of indexes String writes before it truncates.
+const maxStringBits = 64
+
+// String returns the indexes of the set bits in set notation, such as "{1, 5, 100}".
+// Sets with more than 64 bits set are truncated: all of the first 64 indexes are written,
+// followed by ", ... +N more", so the set of the bits below 1000 ends with "62, 63, ... +936 more}".
+func (b BitSet) String() string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	n := 0
+	b.ForeachSetBit(0, func(i uint) bool {
+		if n == maxStringBits {
+			return true
+		}
+		if n > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(strconv.FormatUint(uint64(i), 10))
+		n++
+		return false
+	})
+	if more := b.onesCount - uint(n); more > 0 {
+		fmt.Fprintf(&sb, ", ... +%d more", more)
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// Format implements fmt.Formatter. The verbs %v and %s write String, %b writes the bits
+// as a binary number with bit 0 rightmost and %x, %X write the same number in hexadecimal.
+// The '#' flag adds the 0b or 0x prefix.
+func (b BitSet) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's':
+		f.Write([]byte(b.String()))
+	case 'b':
+		if f.Flag('#') {
+			f.Write([]byte("0b"))
+		}
+		f.Write(b.appendNumber(nil, 2, unitBitsNum, false))
+	case 'x', 'X':
+		if f.Flag('#') {
+			if verb == 'x' {
+				f.Write([]byte("0x"))
+			} else {
+				f.Write([]byte("0X"))
+			}
+		}
+		f.Write(b.appendNumber(nil, 16, unitBitsNum/4, verb == 'X'))
+	default:
+		fmt.Fprintf(f, "%%!%c(bitset.BitSet=%s)", verb, b.String())
+	}
+}
+
+// appendNumber appends the words as one number in base, the most significant word first.
+// width is the number of digits of a whole word.
+func (b BitSet) appendNumber(data []byte, base, width int, upper bool) []byte {
+	words := b.usedWords()
+	if len(words) == 0 {
+		return append(data, '0')
+	}
+	start := len(data)
+	data = strconv.AppendUint(data, words[len(words)-1], base)
+	for i := len(words) - 2; i >= 0; i-- {
+		digits := strconv.FormatUint(words[i], base)
+		for j := len(digits); j < width; j++ {
+			data = append(data, '0')
+		}
+		data = append(data, digits...)
+	}
+	if upper {
+		copy(data[start:], strings.ToUpper(string(data[start:])))
+	}
+	return data
+}
+
+// Dump returns the layout of the words for debugging: each word in use with its
+// hexadecimal value and popcount, followed by the cached counts.
+func (b BitSet) Dump() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "BitSet len=%d cap=%d cardinality=%d length=%d\n",
+		len(b.values), cap(b.values), b.onesCount, b.Length())
+	for i, v := range b.values {
+		fmt.Fprintf(&sb, "[%d] bits %d-%d: %#016x popcount=%d\n",
+			i, i<<unitByteSize, i<<unitByteSize+unitBitsMask, v, bits.OnesCount64(v))
+	}
+	return sb.String()
+}
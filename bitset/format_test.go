@@ -0,0 +1,72 @@
+package bitset
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var (
+	_ fmt.Stringer  = (*BitSet)(nil)
+	_ fmt.Formatter = (*BitSet)(nil)
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		b    *BitSet
+		want string
+	}{
+		{New(), "{}"},
+		{fromList(1, 5, 100), "{1, 5, 100}"},
+	}
+	for _, tt := range tests {
+		if s := tt.b.String(); s != tt.want {
+			t.Errorf("String = %s, want %s", s, tt.want)
+		}
+	}
+
+	b := New()
+	b.SetRange(0, 1000)
+	s := b.String()
+	if !strings.HasPrefix(s, "{0, 1, 2, ") || !strings.HasSuffix(s, ", 63, ... +936 more}") {
+		t.Errorf("String = %s", s)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	b := fromList(0, 2, 64, 68)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%v", "{0, 2, 64, 68}"},
+		{"%s", "{0, 2, 64, 68}"},
+		{"%x", "110000000000000005"},
+		{"%#X", "0X110000000000000005"},
+		{"%b", "10001" + strings.Repeat("0", 61) + "101"},
+		{"%#b", "0b10001" + strings.Repeat("0", 61) + "101"},
+		{"%d", "%!d(bitset.BitSet={0, 2, 64, 68})"},
+	}
+	for _, tt := range tests {
+		if s := fmt.Sprintf(tt.format, b); s != tt.want {
+			t.Errorf("Sprintf(%s) = %s, want %s", tt.format, s, tt.want)
+		}
+	}
+	if s := fmt.Sprintf("%x %b", New(), BitSet{}); s != "0 0" {
+		t.Errorf("Sprintf of empty set = %s, want 0 0", s)
+	}
+}
+
+func TestDump(t *testing.T) {
+	b := NewSize(192)
+	b.Set(1)
+	b.Set(2)
+	b.Set(130)
+	want := "BitSet len=3 cap=3 cardinality=3 length=131\n" +
+		"[0] bits 0-63: 0x0000000000000006 popcount=2\n" +
+		"[1] bits 64-127: 0x0000000000000000 popcount=0\n" +
+		"[2] bits 128-191: 0x0000000000000004 popcount=1\n"
+	if s := b.Dump(); s != want {
+		t.Errorf("Dump = %s, want %s", s, want)
+	}
+}
@@ -0,0 +1,282 @@
+package bitset
+
+import (
+	"math/rand"
+	"testing"
+)
+
+const (
+	opSet = iota
+	opClear
+	opFlip
+	opTestAndSet
+	opTestAndClear
+	opSetRange
+	opClearRange
+	opFlipRange
+	opCountRange
+	opNext
+	opPrev
+	opAnd
+	opOr
+	opXor
+	opAndNot
+	opShiftLeft
+	opShiftRight
+	opRank
+	opSetMany
+	opClearMany
+	opCompare
+	opMarshal
+	opReset
+	opCompact
+	opResize
+	opCount
+)
+
+// step applies the operation op with the arguments x and y to both b and o,
+// and compares the results of the queries.
+func step(t *testing.T, b *BitSet, o oracle, op int, x, y uint) {
+	t.Helper()
+	lo, hi := x, y
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	switch op {
+	case opSet:
+		b.Set(x)
+		o.set(x, true)
+	case opClear:
+		b.Clear(x)
+		o.set(x, false)
+	case opFlip:
+		b.Flip(x)
+		o.set(x, !o[x])
+	case opTestAndSet:
+		if b.TestAndSet(x) != o[x] {
+			t.Fatalf("TestAndSet(%d) = %v", x, !o[x])
+		}
+		o.set(x, true)
+	case opTestAndClear:
+		if b.TestAndClear(x) != o[x] {
+			t.Fatalf("TestAndClear(%d) = %v", x, !o[x])
+		}
+		o.set(x, false)
+	case opSetRange, opClearRange, opFlipRange:
+		switch op {
+		case opSetRange:
+			b.SetRange(lo, hi)
+		case opClearRange:
+			b.ClearRange(lo, hi)
+		case opFlipRange:
+			b.FlipRange(lo, hi)
+		}
+		for i := lo; i < hi; i++ {
+			o.set(i, op == opSetRange || op == opFlipRange && !o[i])
+		}
+	case opCountRange:
+		var n uint
+		for i := range o {
+			if i >= lo && i < hi {
+				n++
+			}
+		}
+		if c := b.CountRange(lo, hi); c != n {
+			t.Fatalf("CountRange(%d, %d) = %d, want %d", lo, hi, c, n)
+		}
+	case opNext:
+		next, nextClear := uint(0), x
+		found := false
+		for i := range o {
+			if i >= x && (!found || i < next) {
+				next, found = i, true
+			}
+		}
+		for o[nextClear] {
+			nextClear++
+		}
+		if i, ok := b.NextSetBit(x); ok != found || i != next {
+			t.Fatalf("NextSetBit(%d) = %d, %v, want %d, %v", x, i, ok, next, found)
+		}
+		if i := b.NextClearBit(x); i != nextClear {
+			t.Fatalf("NextClearBit(%d) = %d, want %d", x, i, nextClear)
+		}
+	case opPrev:
+		prev := uint(0)
+		found := false
+		for i := range o {
+			if i <= x && (!found || i > prev) {
+				prev, found = i, true
+			}
+		}
+		if i, ok := b.PrevSetBit(x); ok != found || i != prev {
+			t.Fatalf("PrevSetBit(%d) = %d, %v, want %d, %v", x, i, ok, prev, found)
+		}
+		prevClear, clearFound := x, true
+		for o[prevClear] {
+			if prevClear == 0 {
+				clearFound = false
+				break
+			}
+			prevClear--
+		}
+		if i, ok := b.PrevClearBit(x); ok != clearFound || ok && i != prevClear {
+			t.Fatalf("PrevClearBit(%d) = %d, %v, want %d, %v", x, i, ok, prevClear, clearFound)
+		}
+	case opAnd, opOr, opXor, opAndNot:
+		other := oracle{}
+		r := rand.New(rand.NewSource(int64(x)))
+		for i := 0; i < int(y%64); i++ {
+			other[uint(r.Intn(int(x)+1))] = true
+		}
+		ob := fromList(other.indexes()...)
+		switch op {
+		case opAnd:
+			b.And(ob)
+			for i := range o {
+				o.set(i, other[i])
+			}
+		case opOr:
+			b.Or(ob)
+			for i := range other {
+				o.set(i, true)
+			}
+		case opXor:
+			b.Xor(ob)
+			for i := range other {
+				o.set(i, !o[i])
+			}
+		case opAndNot:
+			b.AndNot(ob)
+			for i := range other {
+				o.set(i, false)
+			}
+		}
+		checkInvariants(t, "argument", ob, other)
+	case opShiftLeft:
+		b.ShiftLeft(y % 200)
+		shifted := oracle{}
+		for i := range o {
+			shifted[i+y%200] = true
+		}
+		for i := range o {
+			delete(o, i)
+		}
+		for i := range shifted {
+			o[i] = true
+		}
+	case opShiftRight:
+		b.ShiftRight(y % 200)
+		shifted := oracle{}
+		for i := range o {
+			if i >= y%200 {
+				shifted[i-y%200] = true
+			}
+		}
+		for i := range o {
+			delete(o, i)
+		}
+		for i := range shifted {
+			o[i] = true
+		}
+	case opRank:
+		if y%2 == 0 {
+			b.Freeze()
+		}
+		var rank uint
+		for i := range o {
+			if i < x {
+				rank++
+			}
+		}
+		if r := b.Rank(x); r != rank {
+			t.Fatalf("Rank(%d) = %d, want %d", x, r, rank)
+		}
+		if o[x] {
+			if s, ok := b.Select(rank); !ok || s != x {
+				t.Fatalf("Select(%d) = %d, %v, want %d", rank, s, ok, x)
+			}
+		}
+	case opSetMany, opClearMany:
+		indexes := []uint{x, y, lo + 1, hi + 64}
+		if op == opSetMany {
+			b.SetMany(indexes)
+		} else {
+			b.ClearMany(indexes)
+		}
+		for _, i := range indexes {
+			o.set(i, op == opSetMany)
+		}
+	case opCompare:
+		ob := fromList(o.indexes()...)
+		if !b.Equal(ob) || !b.IsSubsetOf(ob) || !b.IsSupersetOf(ob) {
+			t.Fatal("Equal to the oracle set")
+		}
+		if b.Intersects(ob) != (len(o) > 0) {
+			t.Fatal("Intersects the oracle set")
+		}
+	case opMarshal:
+		data, err := b.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var c BitSet
+		if err := c.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		checkInvariants(t, "UnmarshalBinary", &c, o)
+		text, _ := b.MarshalText()
+		if err := c.UnmarshalText(text); err != nil {
+			t.Fatal(err)
+		}
+		checkInvariants(t, "UnmarshalText", &c, o)
+	case opReset:
+		if x%8 == 0 {
+			b.Reset()
+			for i := range o {
+				delete(o, i)
+			}
+		}
+	case opCompact:
+		b.Compact()
+	case opResize:
+		b.Resize(y)
+		for i := range o {
+			if i >= y {
+				delete(o, i)
+			}
+		}
+	}
+	checkInvariants(t, "step", b, o)
+}
+
+func TestRandomOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 20; round++ {
+		b := NewSize(uint(r.Intn(300)))
+		o := oracle{}
+		for i := 0; i < 500; i++ {
+			op := r.Intn(opCount)
+			x := uint(r.Intn(400))
+			y := uint(r.Intn(400))
+			step(t, b, o, op, x, y)
+		}
+	}
+}
+
+func FuzzBitSet(f *testing.F) {
+	f.Add([]byte{0, 5, 0, 1, 70, 0, 5, 10, 200})
+	f.Add([]byte{5, 0, 255, 15, 0, 70, 16, 0, 1, 17, 3, 9})
+	f.Add([]byte{24, 128, 1, 0, 63, 64, 8, 0, 255})
+	f.Fuzz(func(t *testing.T, program []byte) {
+		b := New()
+		o := oracle{}
+		for ; len(program) >= 3; program = program[3:] {
+			op := int(program[0]) % opCount
+			// scale the arguments so that they cross word boundaries.
+			x := uint(program[1]) * 3 / 2
+			y := uint(program[2]) * 3 / 2
+			step(t, b, o, op, x, y)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+//go:build go1.23
+
+package bitset
+
+import "iter"
+
+// All returns an iterator over the indexes of the bits set to true, in ascending order.
+//
+//	for i := range b.All() {
+//		fmt.Println(i)
+//	}
+func (b *BitSet) All() iter.Seq[uint] {
+	return func(yield func(uint) bool) {
+		b.ForeachSetBit(0, func(i uint) bool {
+			return !yield(i)
+		})
+	}
+}
+
+// Backward returns an iterator over the indexes of the bits set to true, in descending order.
+func (b *BitSet) Backward() iter.Seq[uint] {
+	return func(yield func(uint) bool) {
+		n := b.Length()
+		if n == 0 {
+			return
+		}
+		b.ForeachSetBitReverse(uint(n-1), func(i uint) bool {
+			return !yield(i)
+		})
+	}
+}
+
+// ClearBits returns an iterator over the indexes of the bits set to false below Length,
+// in ascending order.
+func (b *BitSet) ClearBits() iter.Seq[uint] {
+	return func(yield func(uint) bool) {
+		n := uint(b.Length())
+		for i := b.NextClearBit(0); i < n; i = b.NextClearBit(i + 1) {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
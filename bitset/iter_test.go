@@ -0,0 +1,38 @@
+//go:build go1.23
+
+package bitset
+
+import "testing"
+
+func TestAll(t *testing.T) {
+	b := fromList(0, 2, 64, 130)
+	var got []uint
+	for i := range b.All() {
+		got = append(got, i)
+		if i == 64 {
+			break
+		}
+	}
+	if len(got) != 3 || got[0] != 0 || got[1] != 2 || got[2] != 64 {
+		t.Errorf("All = %v, want [0 2 64]", got)
+	}
+
+	got = got[:0]
+	for i := range b.Backward() {
+		got = append(got, i)
+	}
+	if len(got) != 4 || got[0] != 130 || got[3] != 0 {
+		t.Errorf("Backward = %v, want [130 64 2 0]", got)
+	}
+
+	var clear uint
+	for i := range b.ClearBits() {
+		if b.Get(i) || i >= 130 {
+			t.Fatalf("ClearBits yields %d", i)
+		}
+		clear++
+	}
+	if clear != 131-b.Cardinality() {
+		t.Errorf("ClearBits yields %d bits, want %d", clear, 131-b.Cardinality())
+	}
+}
@@ -0,0 +1,49 @@
+package bitset
+
+// Iterator walks the bits that are set to true in ascending order. It keeps only its
+// position, so it can be stored across calls and sees the changes made to the set
+// after the position.
+//
+//	it := b.Iterator()
+//	for it.Next() {
+//		fmt.Println(it.Value())
+//	}
+type Iterator struct {
+	b     *BitSet
+	next  uint
+	value uint
+	done  bool
+}
+
+// Iterator returns an iterator positioned before the first set bit of b.
+func (b *BitSet) Iterator() *Iterator {
+	return &Iterator{b: b}
+}
+
+// Next advances the iterator to the next set bit and reports whether there is one.
+func (it *Iterator) Next() bool {
+	if it.done {
+		return false
+	}
+	i, ok := it.b.NextSetBit(it.next)
+	if !ok {
+		it.done = true
+		return false
+	}
+	it.value = i
+	it.next = i + 1
+	it.done = it.next == 0 // the last index, nothing can follow it
+	return true
+}
+
+// Value returns the index of the set bit the iterator is positioned at.
+func (it *Iterator) Value() uint {
+	return it.value
+}
+
+// Reset moves the iterator back before the first set bit.
+func (it *Iterator) Reset() {
+	it.next = 0
+	it.value = 0
+	it.done = false
+}
@@ -0,0 +1,29 @@
+package bitset
+
+import "testing"
+
+func TestIterator(t *testing.T) {
+	b := fromList(1, 64, 300)
+	it := b.Iterator()
+	var got []uint
+	for it.Next() {
+		got = append(got, it.Value())
+		if it.Value() == 64 {
+			b.Set(100)
+		}
+	}
+	if len(got) != 4 || got[0] != 1 || got[1] != 64 || got[2] != 100 || got[3] != 300 {
+		t.Errorf("Iterator = %v, want [1 64 100 300]", got)
+	}
+	if it.Next() {
+		t.Error("Next after the end should be false")
+	}
+	it.Reset()
+	if !it.Next() || it.Value() != 1 {
+		t.Errorf("Reset, Next = %d, want 1", it.Value())
+	}
+
+	if New().Iterator().Next() {
+		t.Error("Next of empty set should be false")
+	}
+}
@@ -0,0 +1,215 @@
+package bitset
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// The mapped file starts with a mappedHeader padded to mappedHeaderSize bytes,
+// followed by the words in host byte order.
+const (
+	mappedMagic      = "BSMM"
+	mappedVersion    = 1
+	mappedHeaderSize = 64
+)
+
+type mappedHeader struct {
+	magic     [4]byte
+	version   uint32
+	width     uint64
+	onesCount uint64
+}
+
+// MappedBitSet is a bit set of a fixed width stored in a memory-mapped file, so
+// bitmaps larger than the heap can be used in place and persist across runs.
+// The words are kept in host byte order, the file is not portable across
+// architectures of different endianness.
+type MappedBitSet struct {
+	set    BitSet // values are the mapped words
+	width  uint
+	file   *os.File
+	data   []byte
+	header *mappedHeader
+}
+
+// CreateMapped creates or truncates the file at path and maps a bit set that
+// holds the bits below width. All bits are false.
+func CreateMapped(path string, width uint) (*MappedBitSet, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	n := (width + unitBitsMask) >> unitByteSize
+	if err := f.Truncate(int64(mappedHeaderSize + n*wordByteSize)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	m, err := mapFile(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	copy(m.header.magic[:], mappedMagic)
+	m.header.version = mappedVersion
+	m.header.width = uint64(width)
+	m.init()
+	return m, nil
+}
+
+// OpenMapped maps the bit set stored in the file at path by CreateMapped.
+func OpenMapped(path string) (*MappedBitSet, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	m, err := mapFile(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := m.check(); err != nil {
+		m.Close()
+		return nil, err
+	}
+	m.init()
+	return m, nil
+}
+
+func mapFile(f *os.File) (*MappedBitSet, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if fi.Size() < mappedHeaderSize {
+		return nil, ErrTruncated
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(fi.Size()), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	return &MappedBitSet{
+		file:   f,
+		data:   data,
+		header: (*mappedHeader)(unsafe.Pointer(&data[0])),
+	}, nil
+}
+
+func (m *MappedBitSet) check() error {
+	if string(m.header.magic[:]) != mappedMagic {
+		return ErrCorrupt
+	}
+	if m.header.version != mappedVersion {
+		return ErrUnsupportedVersion
+	}
+	// compare without rounding the width up, which overflows near 2^64
+	words := uint64(len(m.data)-mappedHeaderSize) / wordByteSize
+	if m.header.width > words<<unitByteSize {
+		return ErrTruncated
+	}
+	return nil
+}
+
+func (m *MappedBitSet) init() {
+	m.width = uint(m.header.width)
+	n := int((m.width + unitBitsMask) >> unitByteSize)
+	if n > 0 {
+		m.set.values = unsafe.Slice((*uint64)(unsafe.Pointer(&m.data[mappedHeaderSize])), n)
+		if r := m.width & unitBitsMask; r != 0 {
+			// bits past the width are not part of the set
+			m.set.values[n-1] &= 1<<r - 1
+		}
+	}
+	// the stored count is not trusted, recount it
+	m.set.onesCount = popcount(m.set.values)
+	m.header.onesCount = uint64(m.set.onesCount)
+	m.set.trim()
+}
+
+// Width returns the number of bits the set can hold. Indexes must be less than it.
+func (m *MappedBitSet) Width() uint {
+	return m.width
+}
+
+// Set index to 1. It returns ErrOutOfRange if index is not less than Width.
+func (m *MappedBitSet) Set(index uint) error {
+	if index >= m.width {
+		return ErrOutOfRange
+	}
+	m.set.Set(index)
+	m.header.onesCount = uint64(m.set.onesCount)
+	return nil
+}
+
+// Clear sets the bit specified by the index to 0.
+// It returns ErrOutOfRange if index is not less than Width.
+func (m *MappedBitSet) Clear(index uint) error {
+	if index >= m.width {
+		return ErrOutOfRange
+	}
+	m.set.Clear(index)
+	m.header.onesCount = uint64(m.set.onesCount)
+	return nil
+}
+
+// Get true if index is set 1, or return false.
+func (m *MappedBitSet) Get(index uint) bool {
+	return m.set.Get(index)
+}
+
+// Cardinality returns the number of bits set to true.
+func (m *MappedBitSet) Cardinality() uint {
+	return m.set.Cardinality()
+}
+
+// NextSetBit returns the index of the first bit that is set to true that occurs on or after
+// the specified starting index. If no such bit exists then false is returned.
+func (m *MappedBitSet) NextSetBit(fromIndex uint) (uint, bool) {
+	return m.set.NextSetBit(fromIndex)
+}
+
+// NextClearBit returns the index of the first bit that is set to false that occurs on or after
+// the specified starting index. If no such bit exists below Width then false is returned.
+func (m *MappedBitSet) NextClearBit(fromIndex uint) (uint, bool) {
+	i := m.set.NextClearBit(fromIndex)
+	return i, i < m.width
+}
+
+// ForeachSetBit calls the do function for each bit that is set to true.
+// param - do: return true to quit.
+func (m *MappedBitSet) ForeachSetBit(fromIndex uint, do func(uint) bool) {
+	m.set.ForeachSetBit(fromIndex, do)
+}
+
+// Sync flushes the changes to the file. It returns os.ErrClosed after Close.
+func (m *MappedBitSet) Sync() error {
+	if m.data == nil {
+		return os.ErrClosed
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC,
+		uintptr(unsafe.Pointer(&m.data[0])), uintptr(len(m.data)), syscall.MS_SYNC)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// Close flushes the changes, unmaps and closes the file. Calling it again returns os.ErrClosed.
+// After Close the set is empty and has a Width of 0.
+func (m *MappedBitSet) Close() error {
+	if m.data == nil {
+		return os.ErrClosed
+	}
+	err := m.Sync()
+	if e := syscall.Munmap(m.data); err == nil {
+		err = e
+	}
+	if e := m.file.Close(); err == nil {
+		err = e
+	}
+	m.set = BitSet{}
+	m.width = 0
+	m.data = nil
+	m.header = nil
+	return err
+}
@@ -0,0 +1,136 @@
+package bitset
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMappedBitSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bits")
+	m, err := CreateMapped(path, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []uint{0, 63, 64, 999} {
+		if err := m.Set(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Set(1000); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Set(1000) = %v, want %v", err, ErrOutOfRange)
+	}
+	m.Clear(63)
+	m.Clear(999)
+	m.Set(999)
+	if m.Cardinality() != 3 || !m.Get(999) || m.Get(63) {
+		t.Errorf("Cardinality = %d", m.Cardinality())
+	}
+	if i, ok := m.NextSetBit(1); !ok || i != 64 {
+		t.Errorf("NextSetBit(1) = %d, %v, want 64", i, ok)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err = OpenMapped(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if m.Width() != 1000 || m.Cardinality() != 3 {
+		t.Errorf("Width = %d, Cardinality = %d", m.Width(), m.Cardinality())
+	}
+	var got []uint
+	m.ForeachSetBit(0, func(i uint) bool {
+		got = append(got, i)
+		return false
+	})
+	if len(got) != 3 || got[0] != 0 || got[1] != 64 || got[2] != 999 {
+		t.Errorf("ForeachSetBit = %v, want [0 64 999]", got)
+	}
+	for i := uint(0); i < 1000; i++ {
+		m.Set(i)
+	}
+	if _, ok := m.NextClearBit(0); ok {
+		t.Error("NextClearBit of a full set should fail")
+	}
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Close(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("second Close = %v, want %v", err, os.ErrClosed)
+	}
+	if err := m.Sync(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Sync after Close = %v, want %v", err, os.ErrClosed)
+	}
+	if err := m.Set(1); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Set after Close = %v, want %v", err, ErrOutOfRange)
+	}
+}
+
+func TestOpenMappedError(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := OpenMapped(filepath.Join(dir, "none")); !os.IsNotExist(err) {
+		t.Errorf("OpenMapped = %v, want not exist", err)
+	}
+
+	short := filepath.Join(dir, "short")
+	os.WriteFile(short, []byte("BSMM"), 0o644)
+	if _, err := OpenMapped(short); !errors.Is(err, ErrTruncated) {
+		t.Errorf("OpenMapped = %v, want %v", err, ErrTruncated)
+	}
+
+	bad := filepath.Join(dir, "bad")
+	os.WriteFile(bad, make([]byte, mappedHeaderSize), 0o644)
+	if _, err := OpenMapped(bad); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("OpenMapped = %v, want %v", err, ErrCorrupt)
+	}
+
+	cut := filepath.Join(dir, "cut")
+	m, err := CreateMapped(cut, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+	os.Truncate(cut, mappedHeaderSize+8)
+	if _, err := OpenMapped(cut); !errors.Is(err, ErrTruncated) {
+		t.Errorf("OpenMapped = %v, want %v", err, ErrTruncated)
+	}
+
+	// a width near 2^64 must not overflow the size check
+	wide := filepath.Join(dir, "wide")
+	if m, err = CreateMapped(wide, 128); err != nil {
+		t.Fatal(err)
+	}
+	m.header.width = 1<<64 - 1
+	m.Close()
+	if _, err := OpenMapped(wide); !errors.Is(err, ErrTruncated) {
+		t.Errorf("OpenMapped = %v, want %v", err, ErrTruncated)
+	}
+}
+
+func TestOpenMappedRecount(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bits")
+	m, err := CreateMapped(path, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Set(5)
+	m.header.onesCount = 1 << 40
+	m.set.values[:2][1] |= 1 << 63 // bit 127, past the width
+	m.Close()
+
+	m, err = OpenMapped(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if m.Cardinality() != 1 || m.Get(127) {
+		t.Errorf("Cardinality = %d, Get(127) = %v, want 1, false", m.Cardinality(), m.Get(127))
+	}
+}
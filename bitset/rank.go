@@ -0,0 +1,87 @@
+package bitset
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// blockWords is the number of words counted by one entry of the rank directory.
+const blockWords = 8
+
+// rankIndex is a directory of the number of set bits before each block of blockWords words.
+type rankIndex struct {
+	blocks []uint // blocks[i] is the number of set bits in values[:i*blockWords]
+}
+
+// Freeze builds an auxiliary popcount directory so that Rank and Select run in near-constant
+// time. The directory costs one uint per 512 bits and is dropped by any later mutation of b,
+// after which Rank and Select scan the words again until Freeze is called once more.
+func (b *BitSet) Freeze() {
+	n := (len(b.values) + blockWords - 1) / blockWords
+	blocks := make([]uint, n+1)
+	var ones uint
+	for i := 0; i < n; i++ {
+		blocks[i] = ones
+		end := (i + 1) * blockWords
+		if end > len(b.values) {
+			end = len(b.values)
+		}
+		ones += popcount(b.values[i*blockWords : end])
+	}
+	blocks[n] = ones
+	b.index = &rankIndex{blocks: blocks}
+}
+
+// Frozen reports whether b has an up to date directory built by Freeze.
+func (b BitSet) Frozen() bool {
+	return b.index != nil
+}
+
+// Rank returns the number of bits set to true before the specified index.
+func (b BitSet) Rank(index uint) uint {
+	unitIndex := index >> unitByteSize
+	if unitIndex >= uint(len(b.values)) {
+		return b.onesCount
+	}
+	var n uint
+	start := uint(0)
+	if b.index != nil {
+		start = unitIndex / blockWords * blockWords
+		n = b.index.blocks[unitIndex/blockWords]
+	}
+	n += popcount(b.values[start:unitIndex])
+	return n + uint(bits.OnesCount64(b.values[unitIndex]&(1<<(index&unitBitsMask)-1)))
+}
+
+// Select returns the index of the k-th bit that is set to true, counting from 0.
+// If k is not less than Cardinality then false is returned.
+func (b BitSet) Select(k uint) (uint, bool) {
+	if k >= b.onesCount {
+		return 0, false
+	}
+	unitIndex := 0
+	if b.index != nil {
+		// find the last block that starts with at most k bits before it.
+		block := sort.Search(len(b.index.blocks), func(i int) bool {
+			return b.index.blocks[i] > k
+		}) - 1
+		k -= b.index.blocks[block]
+		unitIndex = block * blockWords
+	}
+	for ; ; unitIndex++ {
+		v := b.values[unitIndex]
+		c := uint(bits.OnesCount64(v))
+		if k < c {
+			return uint(unitIndex)<<unitByteSize + selectInWord(v, k), true
+		}
+		k -= c
+	}
+}
+
+// selectInWord returns the position of the k-th set bit of v, which must have more than k set bits.
+func selectInWord(v uint64, k uint) uint {
+	for ; k > 0; k-- {
+		v &= v - 1
+	}
+	return uint(bits.TrailingZeros64(v))
+}
@@ -0,0 +1,93 @@
+package bitset
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRankSelect(t *testing.T) {
+	b := New()
+	for i := 0; i < 3000; i++ {
+		b.Set(uint(rand.Intn(100000)))
+	}
+	b.SetRange(50000, 52000)
+	for _, frozen := range []bool{false, true} {
+		if frozen {
+			b.Freeze()
+		}
+		if b.Frozen() != frozen {
+			t.Fatalf("Frozen = %v, want %v", b.Frozen(), frozen)
+		}
+		var rank uint
+		for i := uint(0); i < 110000; i++ {
+			if r := b.Rank(i); r != rank {
+				t.Fatalf("Rank(%d) = %d, want %d", i, r, rank)
+			}
+			if b.Get(i) {
+				if s, ok := b.Select(rank); !ok || s != i {
+					t.Fatalf("Select(%d) = %d, %v, want %d", rank, s, ok, i)
+				}
+				rank++
+			}
+		}
+		if _, ok := b.Select(b.Cardinality()); ok {
+			t.Errorf("Select(%d) should fail", b.Cardinality())
+		}
+	}
+}
+
+func TestFreezeInvalidate(t *testing.T) {
+	b := fromList(1, 1000, 5000)
+	b.Freeze()
+	if r := b.Rank(5000); r != 2 {
+		t.Errorf("Rank(5000) = %d, want 2", r)
+	}
+	b.Set(10)
+	if b.Frozen() {
+		t.Error("Set should drop the directory")
+	}
+	if r := b.Rank(5000); r != 3 {
+		t.Errorf("Rank(5000) = %d, want 3", r)
+	}
+	b.Freeze()
+	b.Set(10)
+	if !b.Frozen() {
+		t.Error("Set of a set bit should keep the directory")
+	}
+	b.ClearRange(0, 2000)
+	if b.Frozen() {
+		t.Error("ClearRange should drop the directory")
+	}
+	if s, ok := b.Select(0); !ok || s != 5000 {
+		t.Errorf("Select(0) = %d, %v, want 5000", s, ok)
+	}
+
+	var e BitSet
+	e.Freeze()
+	if r := e.Rank(100); r != 0 {
+		t.Errorf("Rank(100) = %d, want 0", r)
+	}
+	if _, ok := e.Select(0); ok {
+		t.Error("Select(0) of empty set should fail")
+	}
+}
+
+func BenchmarkRank(b *testing.B) {
+	s := newBitSet()
+	s.Freeze()
+	n := perm(N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Rank(n[i%N])
+	}
+}
+
+func BenchmarkSelect(b *testing.B) {
+	s := newBitSet()
+	s.Freeze()
+	n := perm(int(s.Cardinality()))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Select(n[i%len(n)])
+	}
+}
@@ -0,0 +1,530 @@
+package bitset
+
+import (
+	"math/bits"
+	"sort"
+)
+
+const (
+	chunkBits  = 16
+	chunkMask  = 1<<chunkBits - 1
+	chunkWords = 1 << chunkBits >> unitByteSize
+
+	// arrayMaxSize is the largest cardinality held by an array container,
+	// above it a bitmap container takes less memory.
+	arrayMaxSize = 4096
+)
+
+// Roaring is a compressed bit set for sparse or huge index spaces. The index space is split
+// into chunks of 65536 bits, and each chunk that has any bit set is held as a sorted array,
+// a plain bitmap or a list of runs, whichever is the most compact.
+// Unlike BitSet, setting a high index does not allocate the space below it.
+type Roaring struct {
+	keys       []uint64 // sorted high bits of the indexes
+	containers []container
+	onesCount  uint
+}
+
+// container holds the low 16 bits of the indexes in one chunk.
+type container interface {
+	add(x uint16) (container, bool)
+	remove(x uint16) (container, bool)
+	contains(x uint16) bool
+	cardinality() int
+	// next returns the first value on or after x.
+	next(x uint16) (uint16, bool)
+	// foreach calls do for each value on or after x, plus base. It returns true if do quit.
+	foreach(x uint16, base uint, do func(uint) bool) bool
+	// fill sets the values in the chunk of words, which holds chunkWords words.
+	fill(words []uint64)
+	// optimize returns the most compact container holding the same values.
+	optimize() container
+}
+
+// NewRoaring creates a new compressed bit set. All bits are false.
+func NewRoaring() *Roaring {
+	return &Roaring{}
+}
+
+// NewRoaringFromBitSet creates a compressed bit set holding the same bits as b.
+func NewRoaringFromBitSet(b *BitSet) *Roaring {
+	r := NewRoaring()
+	for start := 0; start < len(b.values); start += chunkWords {
+		end := start + chunkWords
+		if end > len(b.values) {
+			end = len(b.values)
+		}
+		words := b.values[start:end]
+		n := int(popcount(words))
+		if n == 0 {
+			continue
+		}
+		c := &bitmapContainer{n: n}
+		copy(c.words[:], words)
+		r.keys = append(r.keys, uint64(start/chunkWords))
+		r.containers = append(r.containers, c.optimize())
+		r.onesCount += uint(n)
+	}
+	return r
+}
+
+// ToBitSet returns a BitSet holding the same bits as r.
+func (r *Roaring) ToBitSet() *BitSet {
+	b := New()
+	if len(r.keys) == 0 {
+		return b
+	}
+	b.grow(int(r.keys[len(r.keys)-1]+1) * chunkWords)
+	for i, key := range r.keys {
+		offset := int(key) * chunkWords
+		r.containers[i].fill(b.values[offset : offset+chunkWords])
+	}
+	b.onesCount = r.onesCount
+	b.trim()
+	return b
+}
+
+func (r *Roaring) find(key uint64) (int, bool) {
+	i := sort.Search(len(r.keys), func(i int) bool {
+		return r.keys[i] >= key
+	})
+	return i, i < len(r.keys) && r.keys[i] == key
+}
+
+// Set index to 1.
+func (r *Roaring) Set(index uint) {
+	key := uint64(index >> chunkBits)
+	i, found := r.find(key)
+	if !found {
+		r.keys = append(r.keys, 0)
+		copy(r.keys[i+1:], r.keys[i:])
+		r.keys[i] = key
+		r.containers = append(r.containers, nil)
+		copy(r.containers[i+1:], r.containers[i:])
+		r.containers[i] = &arrayContainer{}
+	}
+	c, ok := r.containers[i].add(uint16(index & chunkMask))
+	r.containers[i] = c
+	if ok {
+		r.onesCount++
+	}
+}
+
+// Get true if index is set 1, or return false.
+func (r *Roaring) Get(index uint) bool {
+	i, found := r.find(uint64(index >> chunkBits))
+	return found && r.containers[i].contains(uint16(index&chunkMask))
+}
+
+// Clear sets the bit specified by the index to 0.
+func (r *Roaring) Clear(index uint) {
+	i, found := r.find(uint64(index >> chunkBits))
+	if !found {
+		return
+	}
+	c, ok := r.containers[i].remove(uint16(index & chunkMask))
+	if !ok {
+		return
+	}
+	r.onesCount--
+	if c.cardinality() == 0 {
+		r.keys = append(r.keys[:i], r.keys[i+1:]...)
+		copy(r.containers[i:], r.containers[i+1:])
+		r.containers[len(r.containers)-1] = nil
+		r.containers = r.containers[:len(r.containers)-1]
+		return
+	}
+	r.containers[i] = c
+}
+
+// Reset all bits to 0.
+func (r *Roaring) Reset() {
+	r.keys = nil
+	r.containers = nil
+	r.onesCount = 0
+}
+
+// Cardinality returns the number of bits set to true.
+func (r *Roaring) Cardinality() uint {
+	return r.onesCount
+}
+
+// RunOptimize converts each chunk to the most compact container, using runs of
+// consecutive set bits where they save memory.
+func (r *Roaring) RunOptimize() {
+	for i, c := range r.containers {
+		r.containers[i] = c.optimize()
+	}
+}
+
+// NextSetBit returns the index of the first bit that is set to true that occurs on or after
+// the specified starting index. If no such bit exists then false is returned.
+func (r *Roaring) NextSetBit(fromIndex uint) (uint, bool) {
+	key := uint64(fromIndex >> chunkBits)
+	i, found := r.find(key)
+	if found {
+		if x, ok := r.containers[i].next(uint16(fromIndex & chunkMask)); ok {
+			return uint(key)<<chunkBits + uint(x), true
+		}
+		i++
+	}
+	if i < len(r.keys) {
+		x, _ := r.containers[i].next(0)
+		return uint(r.keys[i])<<chunkBits + uint(x), true
+	}
+	return 0, false
+}
+
+// ForeachSetBit calls the do function for each bit that is set to true on or after fromIndex.
+// param - do: return true to quit.
+func (r *Roaring) ForeachSetBit(fromIndex uint, do func(uint) bool) {
+	key := uint64(fromIndex >> chunkBits)
+	i, found := r.find(key)
+	if found {
+		if r.containers[i].foreach(uint16(fromIndex&chunkMask), uint(key)<<chunkBits, do) {
+			return
+		}
+		i++
+	}
+	for ; i < len(r.keys); i++ {
+		if r.containers[i].foreach(0, uint(r.keys[i])<<chunkBits, do) {
+			return
+		}
+	}
+}
+
+// arrayContainer holds a sorted array of values.
+type arrayContainer struct {
+	values []uint16
+}
+
+func (c *arrayContainer) search(x uint16) (int, bool) {
+	i := sort.Search(len(c.values), func(i int) bool {
+		return c.values[i] >= x
+	})
+	return i, i < len(c.values) && c.values[i] == x
+}
+
+func (c *arrayContainer) add(x uint16) (container, bool) {
+	i, found := c.search(x)
+	if found {
+		return c, false
+	}
+	if len(c.values) >= arrayMaxSize {
+		b := c.toBitmap()
+		b.add(x)
+		return b, true
+	}
+	c.values = append(c.values, 0)
+	copy(c.values[i+1:], c.values[i:])
+	c.values[i] = x
+	return c, true
+}
+
+func (c *arrayContainer) remove(x uint16) (container, bool) {
+	i, found := c.search(x)
+	if !found {
+		return c, false
+	}
+	c.values = append(c.values[:i], c.values[i+1:]...)
+	return c, true
+}
+
+func (c *arrayContainer) contains(x uint16) bool {
+	_, found := c.search(x)
+	return found
+}
+
+func (c *arrayContainer) cardinality() int {
+	return len(c.values)
+}
+
+func (c *arrayContainer) next(x uint16) (uint16, bool) {
+	i, _ := c.search(x)
+	if i < len(c.values) {
+		return c.values[i], true
+	}
+	return 0, false
+}
+
+func (c *arrayContainer) foreach(x uint16, base uint, do func(uint) bool) bool {
+	i, _ := c.search(x)
+	for _, v := range c.values[i:] {
+		if do(base + uint(v)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *arrayContainer) fill(words []uint64) {
+	for _, v := range c.values {
+		words[v>>unitByteSize] |= 1 << (v & unitBitsMask)
+	}
+}
+
+func (c *arrayContainer) toBitmap() *bitmapContainer {
+	b := &bitmapContainer{}
+	c.fill(b.words[:])
+	b.n = len(c.values)
+	return b
+}
+
+func (c *arrayContainer) numRuns() int {
+	n := 0
+	for i, v := range c.values {
+		if i == 0 || c.values[i-1]+1 != v {
+			n++
+		}
+	}
+	return n
+}
+
+func (c *arrayContainer) optimize() container {
+	if runs := c.numRuns(); runSizeInBytes(runs) < arraySizeInBytes(len(c.values)) {
+		r := &runContainer{runs: make([]interval, 0, runs)}
+		for i, v := range c.values {
+			if i == 0 || c.values[i-1]+1 != v {
+				r.runs = append(r.runs, interval{start: v, last: v})
+			} else {
+				r.runs[len(r.runs)-1].last = v
+			}
+		}
+		return r
+	}
+	return c
+}
+
+// bitmapContainer holds one bit for every value of the chunk.
+type bitmapContainer struct {
+	words [chunkWords]uint64
+	n     int
+}
+
+func (c *bitmapContainer) add(x uint16) (container, bool) {
+	w, m := x>>unitByteSize, uint64(1)<<(x&unitBitsMask)
+	if c.words[w]&m != 0 {
+		return c, false
+	}
+	c.words[w] |= m
+	c.n++
+	return c, true
+}
+
+func (c *bitmapContainer) remove(x uint16) (container, bool) {
+	w, m := x>>unitByteSize, uint64(1)<<(x&unitBitsMask)
+	if c.words[w]&m == 0 {
+		return c, false
+	}
+	c.words[w] &^= m
+	c.n--
+	if c.n <= arrayMaxSize {
+		return c.toArray(), true
+	}
+	return c, true
+}
+
+func (c *bitmapContainer) contains(x uint16) bool {
+	return c.words[x>>unitByteSize]&(1<<(x&unitBitsMask)) != 0
+}
+
+func (c *bitmapContainer) cardinality() int {
+	return c.n
+}
+
+func (c *bitmapContainer) next(x uint16) (uint16, bool) {
+	index := int(x >> unitByteSize)
+	v := c.words[index] & (unitMask << (x & unitBitsMask))
+	for {
+		if v != 0 {
+			return uint16(index<<unitByteSize + bits.TrailingZeros64(v)), true
+		}
+		index++
+		if index >= chunkWords {
+			return 0, false
+		}
+		v = c.words[index]
+	}
+}
+
+func (c *bitmapContainer) foreach(x uint16, base uint, do func(uint) bool) bool {
+	index := int(x >> unitByteSize)
+	v := c.words[index] & (unitMask << (x & unitBitsMask))
+	for {
+		for v != 0 {
+			if do(base + uint(index<<unitByteSize+bits.TrailingZeros64(v))) {
+				return true
+			}
+			v &= v - 1
+		}
+		index++
+		if index >= chunkWords {
+			return false
+		}
+		v = c.words[index]
+	}
+}
+
+func (c *bitmapContainer) fill(words []uint64) {
+	copy(words, c.words[:])
+}
+
+func (c *bitmapContainer) toArray() *arrayContainer {
+	a := &arrayContainer{values: make([]uint16, 0, c.n)}
+	for i, v := range c.words {
+		for v != 0 {
+			a.values = append(a.values, uint16(i<<unitByteSize+bits.TrailingZeros64(v)))
+			v &= v - 1
+		}
+	}
+	return a
+}
+
+func (c *bitmapContainer) numRuns() int {
+	n := 0
+	var carry uint64
+	for _, v := range c.words {
+		n += bits.OnesCount64(v &^ (v<<1 | carry)) // the first bit of each run
+		carry = v >> unitBitsMask
+	}
+	return n
+}
+
+func (c *bitmapContainer) optimize() container {
+	runs := c.numRuns()
+	if runSizeInBytes(runs) < bitmapSizeInBytes && runSizeInBytes(runs) < arraySizeInBytes(c.n) {
+		r := &runContainer{runs: make([]interval, 0, runs)}
+		for x, ok := c.next(0); ok; {
+			last := x
+			for last < chunkMask && c.contains(last+1) {
+				last++
+			}
+			r.runs = append(r.runs, interval{start: x, last: last})
+			if last == chunkMask {
+				break
+			}
+			x, ok = c.next(last + 1)
+		}
+		return r
+	}
+	if c.n <= arrayMaxSize {
+		return c.toArray()
+	}
+	return c
+}
+
+// interval is a run of consecutive values from start to last inclusive.
+type interval struct {
+	start, last uint16
+}
+
+// runContainer holds sorted, non-adjacent runs of values. It is only built by optimize,
+// adding or removing a value converts it back to an array or a bitmap.
+type runContainer struct {
+	runs []interval
+}
+
+// search returns the index of the first run whose last value is on or after x.
+func (c *runContainer) search(x uint16) int {
+	return sort.Search(len(c.runs), func(i int) bool {
+		return c.runs[i].last >= x
+	})
+}
+
+func (c *runContainer) add(x uint16) (container, bool) {
+	if c.contains(x) {
+		return c, false
+	}
+	return c.unpack().add(x)
+}
+
+func (c *runContainer) remove(x uint16) (container, bool) {
+	if !c.contains(x) {
+		return c, false
+	}
+	return c.unpack().remove(x)
+}
+
+func (c *runContainer) contains(x uint16) bool {
+	i := c.search(x)
+	return i < len(c.runs) && c.runs[i].start <= x
+}
+
+func (c *runContainer) cardinality() int {
+	n := 0
+	for _, r := range c.runs {
+		n += int(r.last-r.start) + 1
+	}
+	return n
+}
+
+func (c *runContainer) next(x uint16) (uint16, bool) {
+	i := c.search(x)
+	if i >= len(c.runs) {
+		return 0, false
+	}
+	if c.runs[i].start > x {
+		return c.runs[i].start, true
+	}
+	return x, true
+}
+
+func (c *runContainer) foreach(x uint16, base uint, do func(uint) bool) bool {
+	for i := c.search(x); i < len(c.runs); i++ {
+		start := uint(c.runs[i].start)
+		if start < uint(x) {
+			start = uint(x)
+		}
+		for v := start; v <= uint(c.runs[i].last); v++ {
+			if do(base + v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *runContainer) fill(words []uint64) {
+	for _, r := range c.runs {
+		setWordsRange(words, uint(r.start), uint(r.last)+1)
+	}
+}
+
+// unpack converts c to an array or a bitmap container.
+func (c *runContainer) unpack() container {
+	b := &bitmapContainer{n: c.cardinality()}
+	c.fill(b.words[:])
+	if b.n <= arrayMaxSize {
+		return b.toArray()
+	}
+	return b
+}
+
+func (c *runContainer) optimize() container {
+	return c
+}
+
+// setWordsRange sets the bits from fromIndex (inclusive) to toIndex (exclusive) in words.
+func setWordsRange(words []uint64, fromIndex, toIndex uint) {
+	startUnit, endUnit, firstMask, lastMask := rangeUnits(fromIndex, toIndex)
+	for i := startUnit; i <= endUnit; i++ {
+		mask := uint64(unitMask)
+		if i == startUnit {
+			mask &= firstMask
+		}
+		if i == endUnit {
+			mask &= lastMask
+		}
+		words[i] |= mask
+	}
+}
+
+const bitmapSizeInBytes = chunkWords * 8
+
+func arraySizeInBytes(n int) int {
+	return n * 2
+}
+
+func runSizeInBytes(runs int) int {
+	return runs * 4
+}
@@ -0,0 +1,167 @@
+package bitset
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func roaringBits(r *Roaring, from uint) []uint {
+	var l []uint
+	r.ForeachSetBit(from, func(i uint) bool {
+		l = append(l, i)
+		return false
+	})
+	return l
+}
+
+func TestRoaring(t *testing.T) {
+	r := NewRoaring()
+	const huge uint = 4000000000
+	r.Set(huge)
+	r.Set(1)
+	r.Set(70000)
+	if r.Cardinality() != 3 {
+		t.Errorf("Cardinality = %d, want 3", r.Cardinality())
+	}
+	for _, i := range []uint{1, 70000, huge} {
+		if !r.Get(i) {
+			t.Errorf("Get(%d) = false", i)
+		}
+	}
+	if r.Get(2) || r.Get(huge+1) {
+		t.Error("Get of unset bit")
+	}
+	if i, ok := r.NextSetBit(2); !ok || i != 70000 {
+		t.Errorf("NextSetBit(2) = %d, %v, want 70000", i, ok)
+	}
+	if i, ok := r.NextSetBit(70001); !ok || i != huge {
+		t.Errorf("NextSetBit(70001) = %d, %v, want %d", i, ok, huge)
+	}
+	if _, ok := r.NextSetBit(huge + 1); ok {
+		t.Errorf("NextSetBit(%d) should fail", huge+1)
+	}
+	if l := roaringBits(r, 2); len(l) != 2 || l[0] != 70000 || l[1] != huge {
+		t.Errorf("ForeachSetBit(2) = %v", l)
+	}
+	r.Clear(huge)
+	r.Clear(huge)
+	r.Clear(5)
+	if r.Cardinality() != 2 || len(r.keys) != 2 {
+		t.Errorf("Clear Cardinality = %d, chunks = %d", r.Cardinality(), len(r.keys))
+	}
+	r.Reset()
+	if r.Cardinality() != 0 || r.Get(1) {
+		t.Error("Reset")
+	}
+}
+
+func TestRoaringContainers(t *testing.T) {
+	r := NewRoaring()
+	want := New()
+	for i := uint(0); i < 10000; i++ {
+		r.Set(i * 3)
+		want.Set(i * 3)
+	}
+	if _, ok := r.containers[0].(*bitmapContainer); !ok {
+		t.Errorf("dense chunk is %T, want bitmap", r.containers[0])
+	}
+	for i := uint(0); i < 10000; i++ {
+		if i%3 != 0 || i > 8000 {
+			r.Clear(i * 3)
+			want.Clear(i * 3)
+		}
+	}
+	if _, ok := r.containers[0].(*arrayContainer); !ok {
+		t.Errorf("sparse chunk is %T, want array", r.containers[0])
+	}
+
+	r.Reset()
+	want.Reset()
+	for i := uint(100); i < 50000; i++ {
+		r.Set(i)
+		want.Set(i)
+	}
+	r.RunOptimize()
+	if _, ok := r.containers[0].(*runContainer); !ok {
+		t.Errorf("run chunk is %T, want run", r.containers[0])
+	}
+	checkRoaring(t, r, want)
+	r.Set(60000)
+	want.Set(60000)
+	r.Clear(200)
+	want.Clear(200)
+	checkRoaring(t, r, want)
+}
+
+func checkRoaring(t *testing.T, r *Roaring, want *BitSet) {
+	t.Helper()
+	if r.Cardinality() != want.Cardinality() {
+		t.Fatalf("Cardinality = %d, want %d", r.Cardinality(), want.Cardinality())
+	}
+	l := roaringBits(r, 0)
+	i := 0
+	want.ForeachSetBit(0, func(v uint) bool {
+		if i >= len(l) || l[i] != v {
+			t.Fatalf("ForeachSetBit mismatch at %d", v)
+		}
+		i++
+		return false
+	})
+	b := r.ToBitSet()
+	if b.Cardinality() != want.Cardinality() || b.Length() != want.Length() {
+		t.Fatalf("ToBitSet Cardinality = %d, Length = %d", b.Cardinality(), b.Length())
+	}
+	for _, v := range []uint{0, 99, 100, 101, 65535, 65536, 65537, 200000} {
+		if r.Get(v) != want.Get(v) {
+			t.Errorf("Get(%d) = %v", v, r.Get(v))
+		}
+		n1, ok1 := r.NextSetBit(v)
+		n2, ok2 := want.NextSetBit(v)
+		if n1 != n2 || ok1 != ok2 {
+			t.Errorf("NextSetBit(%d) = %d, %v, want %d, %v", v, n1, ok1, n2, ok2)
+		}
+	}
+}
+
+func TestRoaringRandom(t *testing.T) {
+	r := NewRoaring()
+	want := New()
+	for i := 0; i < 100000; i++ {
+		v := uint(rand.Intn(300000))
+		if rand.Intn(3) == 0 {
+			r.Clear(v)
+			want.Clear(v)
+		} else {
+			r.Set(v)
+			want.Set(v)
+		}
+		if i%20000 == 0 {
+			r.RunOptimize()
+		}
+	}
+	want.SetRange(100000, 180000)
+	checkRoaring(t, NewRoaringFromBitSet(want), want)
+	r.RunOptimize()
+	for i := uint(100000); i < 180000; i++ {
+		r.Set(i)
+	}
+	checkRoaring(t, r, want)
+}
+
+func BenchmarkRoaringSet(b *testing.B) {
+	r := NewRoaring()
+	n := perm(N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Set(n[i%N])
+	}
+}
+
+func BenchmarkRoaringGet(b *testing.B) {
+	r := NewRoaringFromBitSet(newBitSet())
+	n := perm(N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Get(n[i%N])
+	}
+}
@@ -0,0 +1,127 @@
+package bitset
+
+import "math/bits"
+
+// ShiftLeft moves every bit n positions up, so bit i becomes bit i+n.
+// The bits below n become 0.
+func (b *BitSet) ShiftLeft(n uint) {
+	if n == 0 || b.onesCount == 0 {
+		return
+	}
+	b.trim()
+	words := int(n >> unitByteSize)
+	shift := n & unitBitsMask
+	old := len(b.values)
+	if shift == 0 {
+		b.grow(old + words)
+		for i := old - 1; i >= 0; i-- {
+			b.values[i+words] = b.values[i]
+		}
+	} else {
+		b.grow(old + words + 1)
+		for i := old - 1; i >= 0; i-- {
+			v := b.values[i]
+			b.values[i+words+1] |= v >> (unitBitsNum - shift)
+			b.values[i+words] = v << shift
+		}
+	}
+	for i := 0; i < words; i++ {
+		b.values[i] = 0
+	}
+	b.index = nil
+	b.trim()
+}
+
+// ShiftRight moves every bit n positions down, so bit i becomes bit i-n.
+// The bits below n are dropped.
+func (b *BitSet) ShiftRight(n uint) {
+	if n == 0 || b.onesCount == 0 {
+		return
+	}
+	b.index = nil
+	words := n >> unitByteSize
+	if words >= uint(len(b.values)) {
+		b.Reset()
+		return
+	}
+	b.onesCount -= b.CountRange(0, n)
+	shift := n & unitBitsMask
+	last := len(b.values) - int(words)
+	for i := 0; i < last; i++ {
+		v := b.values[i+int(words)] >> shift
+		if shift != 0 && i+int(words)+1 < len(b.values) {
+			v |= b.values[i+int(words)+1] << (unitBitsNum - shift)
+		}
+		b.values[i] = v
+	}
+	for i := last; i < len(b.values); i++ {
+		b.values[i] = 0
+	}
+	b.trim()
+}
+
+// Rotate rotates the bits below width left by k positions, so bit i becomes bit
+// (i+k) mod width. To rotate right, call Rotate with a negative k.
+// The bits on or above width are not changed.
+func (b *BitSet) Rotate(k int, width uint) {
+	if width == 0 {
+		return
+	}
+	var r uint
+	if k >= 0 {
+		r = uint(k) % width
+	} else {
+		r = width - uint(-k)%width
+		if r == width {
+			r = 0
+		}
+	}
+	if r == 0 {
+		return
+	}
+	low := b.lowBits(width)
+	high := low.Clone()
+	low.ShiftLeft(r)
+	low.ClearRange(width, ^uint(0))
+	high.ShiftRight(width - r)
+	b.ClearRange(0, width)
+	b.Or(low)
+	b.Or(high)
+}
+
+// Reverse reverses the order of the bits below width, so bit i becomes bit width-1-i.
+// The bits on or above width are not changed.
+func (b *BitSet) Reverse(width uint) {
+	if width < 2 {
+		return
+	}
+	low := b.lowBits(width)
+	if low.onesCount == 0 {
+		return
+	}
+	n := int((width + unitBitsMask) >> unitByteSize)
+	reversed := &BitSet{values: make([]uint64, n), onesCount: low.onesCount}
+	for i, v := range low.values {
+		reversed.values[n-1-i] = bits.Reverse64(v)
+	}
+	// bit i is now at n*64-1-i, move it down to width-1-i.
+	reversed.ShiftRight(uint(n)<<unitByteSize - width)
+	b.ClearRange(0, width)
+	b.Or(reversed)
+}
+
+// lowBits returns a copy of the bits below width.
+func (b BitSet) lowBits(width uint) *BitSet {
+	n := (width + unitBitsMask) >> unitByteSize
+	if n > uint(len(b.values)) {
+		n = uint(len(b.values))
+	}
+	low := &BitSet{values: make([]uint64, n)}
+	copy(low.values, b.values)
+	if n == (width+unitBitsMask)>>unitByteSize && width&unitBitsMask != 0 {
+		low.values[n-1] &= unitMask >> (unitBitsNum - width&unitBitsMask)
+	}
+	low.onesCount = popcount(low.values)
+	low.trim()
+	return low
+}
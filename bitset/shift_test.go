@@ -0,0 +1,79 @@
+package bitset
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShift(t *testing.T) {
+	for _, n := range []uint{0, 1, 7, 63, 64, 65, 128, 200, 1000} {
+		b, m := randomBitSet(300, 1000)
+		c := b.Clone()
+
+		b.ShiftLeft(n)
+		want := oracle{}
+		for i := range m {
+			want[i+n] = true
+		}
+		checkInvariants(t, "ShiftLeft", b, want)
+
+		b.ShiftRight(n)
+		checkInvariants(t, "ShiftLeft, ShiftRight", b, m)
+
+		c.ShiftRight(n)
+		want = oracle{}
+		for i := range m {
+			if i >= n {
+				want[i-n] = true
+			}
+		}
+		checkInvariants(t, "ShiftRight", c, want)
+	}
+
+	b := fromList(3)
+	b.ShiftRight(4)
+	checkBits(t, "ShiftRight all", b)
+	b.ShiftLeft(4)
+	checkBits(t, "ShiftLeft empty", b)
+}
+
+func TestRotate(t *testing.T) {
+	for _, width := range []uint{1, 10, 64, 100, 640} {
+		for _, k := range []int{0, 1, -1, 5, -70, 64, 1000} {
+			b, m := randomBitSet(200, 800)
+			b.Rotate(k, width)
+			want := oracle{}
+			for i := range m {
+				if i < width {
+					i = uint((int(i) + k%int(width) + int(width)) % int(width))
+				}
+				want[i] = true
+			}
+			checkInvariants(t, "Rotate", b, want)
+		}
+	}
+}
+
+func TestReverse(t *testing.T) {
+	for _, width := range []uint{0, 1, 2, 63, 64, 65, 100, 640, 1000} {
+		b, m := randomBitSet(200, 800)
+		b.Reverse(width)
+		want := oracle{}
+		for i := range m {
+			if i < width {
+				i = width - 1 - i
+			}
+			want[i] = true
+		}
+		checkInvariants(t, fmt.Sprint("Reverse ", width), b, want)
+	}
+}
+
+func BenchmarkShiftLeft(b *testing.B) {
+	s := newBitSet()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.ShiftLeft(1)
+		s.ShiftRight(1)
+	}
+}
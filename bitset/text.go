@@ -0,0 +1,209 @@
+package bitset
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Format selects the textual representation of a BitSet in JSON.
+type Format int
+
+const (
+	// FormatRanges writes a string of indexes and inclusive ranges, such as "1-5,100,200-210".
+	FormatRanges Format = iota
+	// FormatIndexes writes an array of the indexes of the set bits, such as [1,2,3].
+	FormatIndexes
+	// FormatBase64 writes a string of the base64 encoded little-endian words, prefixed by "base64:".
+	FormatBase64
+)
+
+const base64Prefix = "base64:"
+
+// maxDecodeBits bounds the indexes accepted in the range and index formats, so that
+// a short input allocates at most 8 MiB of words. The FormatBase64 format is not
+// bounded, as it allocates no more than its own length, so use it for larger sets.
+const maxDecodeBits = 1 << 26
+
+// Formatted wraps a BitSet to marshal it to JSON in the format Format, such as
+// a struct field that should not use the FormatRanges default of MarshalJSON.
+type Formatted struct {
+	Set    *BitSet
+	Format Format
+}
+
+// MarshalJSON implements the json.Marshaler interface using f.Format.
+// A nil Set is written as null.
+func (f Formatted) MarshalJSON() ([]byte, error) {
+	if f.Set == nil {
+		return []byte("null"), nil
+	}
+	return f.Set.MarshalJSONFormat(f.Format)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts any Format.
+func (f *Formatted) UnmarshalJSON(data []byte) error {
+	if f.Set == nil {
+		f.Set = New()
+	}
+	return f.Set.UnmarshalJSON(data)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface, it writes the set bits
+// as a list of indexes and inclusive ranges, such as "1-5,100,200-210".
+func (b BitSet) MarshalText() ([]byte, error) {
+	return b.appendRanges(nil), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// It accepts the text of MarshalText and of the FormatBase64 format.
+// Indexes of the MarshalText format must be less than 1<<26, or ErrCorrupt is returned.
+func (b *BitSet) UnmarshalText(text []byte) error {
+	if bytes.HasPrefix(text, []byte(base64Prefix)) {
+		return b.decodeBase64(text[len(base64Prefix):])
+	}
+	return b.decodeRanges(text)
+}
+
+// MarshalJSON implements the json.Marshaler interface using FormatRanges.
+// Use MarshalJSONFormat or Formatted for the other formats.
+func (b BitSet) MarshalJSON() ([]byte, error) {
+	return b.MarshalJSONFormat(FormatRanges)
+}
+
+// MarshalJSONFormat returns the JSON encoding of b in the format f.
+func (b BitSet) MarshalJSONFormat(f Format) ([]byte, error) {
+	switch f {
+	case FormatRanges:
+		data := append(make([]byte, 0, 16), '"')
+		data = b.appendRanges(data)
+		return append(data, '"'), nil
+	case FormatIndexes:
+		data := append(make([]byte, 0, 16), '[')
+		b.ForeachSetBit(0, func(i uint) bool {
+			if len(data) > 1 {
+				data = append(data, ',')
+			}
+			data = strconv.AppendUint(data, uint64(i), 10)
+			return false
+		})
+		return append(data, ']'), nil
+	case FormatBase64:
+		words := b.usedWords()
+		raw := make([]byte, len(words)*wordByteSize)
+		for i, v := range words {
+			binary.LittleEndian.PutUint64(raw[i*wordByteSize:], v)
+		}
+		data := make([]byte, len(base64Prefix)+base64.StdEncoding.EncodedLen(len(raw))+2)
+		data[0] = '"'
+		copy(data[1:], base64Prefix)
+		base64.StdEncoding.Encode(data[1+len(base64Prefix):], raw)
+		data[len(data)-1] = '"'
+		return data, nil
+	}
+	return nil, fmt.Errorf("bitset: unknown format %d", f)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts any Format.
+func (b *BitSet) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '[' {
+		var indexes []uint64 // not uint, so that large indexes are ErrCorrupt on 32-bit too
+		if err := json.Unmarshal(data, &indexes); err != nil {
+			return err
+		}
+		for _, i := range indexes {
+			if i >= maxDecodeBits {
+				return fmt.Errorf("%w: index %d out of range", ErrCorrupt, i)
+			}
+		}
+		b.Reset()
+		for _, i := range indexes {
+			b.Set(uint(i))
+		}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return b.UnmarshalText([]byte(s))
+}
+
+func (b BitSet) appendRanges(data []byte) []byte {
+	first := true
+	for start, ok := b.NextSetBit(0); ok; start, ok = b.NextSetBit(start) {
+		end := b.NextClearBit(start) - 1
+		if !first {
+			data = append(data, ',')
+		}
+		first = false
+		data = strconv.AppendUint(data, uint64(start), 10)
+		if end > start {
+			data = append(data, '-')
+			data = strconv.AppendUint(data, uint64(end), 10)
+		}
+		start = end + 1
+	}
+	return data
+}
+
+func (b *BitSet) decodeRanges(text []byte) error {
+	n := New()
+	for len(text) > 0 {
+		item := text
+		if i := bytes.IndexByte(text, ','); i >= 0 {
+			item, text = text[:i], text[i+1:]
+			if len(text) == 0 {
+				return fmt.Errorf("%w: trailing comma", ErrCorrupt)
+			}
+		} else {
+			text = nil
+		}
+		lo, hi := item, item
+		if i := bytes.IndexByte(item, '-'); i >= 0 {
+			lo, hi = item[:i], item[i+1:]
+		}
+		start, err := strconv.ParseUint(string(lo), 10, strconv.IntSize)
+		if err != nil {
+			return fmt.Errorf("%w: invalid index %q", ErrCorrupt, lo)
+		}
+		end, err := strconv.ParseUint(string(hi), 10, strconv.IntSize)
+		if err != nil {
+			return fmt.Errorf("%w: invalid index %q", ErrCorrupt, hi)
+		}
+		if end < start || end == uint64(^uint(0)) {
+			return fmt.Errorf("%w: invalid range %q", ErrCorrupt, item)
+		}
+		if end >= maxDecodeBits {
+			return fmt.Errorf("%w: index %d out of range", ErrCorrupt, end)
+		}
+		n.SetRange(uint(start), uint(end)+1)
+	}
+	*b = *n
+	return nil
+}
+
+func (b *BitSet) decodeBase64(text []byte) error {
+	raw := make([]byte, base64.StdEncoding.DecodedLen(len(text)))
+	n, err := base64.StdEncoding.Decode(raw, text)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	raw = raw[:n]
+	if len(raw)%wordByteSize != 0 {
+		return fmt.Errorf("%w: partial word", ErrCorrupt)
+	}
+	values := make([]uint64, len(raw)/wordByteSize)
+	for i := range values {
+		values[i] = binary.LittleEndian.Uint64(raw[i*wordByteSize:])
+	}
+	b.load(values)
+	return nil
+}
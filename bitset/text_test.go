@@ -0,0 +1,136 @@
+package bitset
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+var (
+	_ encoding.TextMarshaler   = (*BitSet)(nil)
+	_ encoding.TextUnmarshaler = (*BitSet)(nil)
+	_ json.Marshaler           = (*BitSet)(nil)
+	_ json.Unmarshaler         = (*BitSet)(nil)
+)
+
+func TestMarshalText(t *testing.T) {
+	b := fromList(1, 2, 3, 4, 5, 100, 200, 201, 202)
+	text, err := b.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(text) != "1-5,100,200-202" {
+		t.Errorf("MarshalText = %s", text)
+	}
+	var c BitSet
+	if err := c.UnmarshalText(text); err != nil {
+		t.Fatal(err)
+	}
+	checkBits(t, "UnmarshalText", &c, 1, 2, 3, 4, 5, 100, 200, 201, 202)
+
+	for _, s := range []string{"1-", "-1", "5-1", "a", "1,", ",1", "1--2", "base64:AQ", "18446744073709551614", "1-18446744073709551614", "4294967296", "4294967295", "0-67108864"} {
+		if err := c.UnmarshalText([]byte(s)); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("UnmarshalText(%q) = %v, want %v", s, err, ErrCorrupt)
+		}
+	}
+	checkBits(t, "UnmarshalText error", &c, 1, 2, 3, 4, 5, 100, 200, 201, 202)
+}
+
+func TestMarshalJSONFormat(t *testing.T) {
+	b := fromList(0, 1, 64)
+	tests := []struct {
+		format Format
+		json   string
+	}{
+		{FormatRanges, `"0-1,64"`},
+		{FormatIndexes, `[0,1,64]`},
+		{FormatBase64, `"base64:AwAAAAAAAAABAAAAAAAAAA=="`},
+	}
+	for _, tt := range tests {
+		data, err := b.MarshalJSONFormat(tt.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.json {
+			t.Errorf("MarshalJSONFormat(%d) = %s, want %s", tt.format, data, tt.json)
+		}
+		var c BitSet
+		if err := json.Unmarshal(data, &c); err != nil {
+			t.Fatal(err)
+		}
+		checkBits(t, tt.json, &c, 0, 1, 64)
+	}
+	if _, err := b.MarshalJSONFormat(Format(-1)); err == nil {
+		t.Error("MarshalJSONFormat(-1) should fail")
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	sparse := New()
+	for i := uint(0); i < 1<<22; i += 100003 {
+		sparse.Set(i)
+	}
+	dense := New()
+	for i := uint(0); i < 100000; i++ {
+		if i%1000 != 999 {
+			dense.Set(i)
+		}
+	}
+	for _, b := range []*BitSet{New(), sparse, dense} {
+		for _, f := range []Format{FormatRanges, FormatIndexes, FormatBase64} {
+			doc := struct {
+				Set *BitSet `json:"set"`
+			}{b}
+			data, err := b.MarshalJSONFormat(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data = append(append([]byte(`{"set":`), data...), '}')
+			doc.Set = New()
+			if err := json.Unmarshal(data, &doc); err != nil {
+				t.Fatal(err)
+			}
+			if doc.Set.Cardinality() != b.Cardinality() || doc.Set.Length() != b.Length() {
+				t.Fatalf("format %d: Cardinality = %d, want %d", f, doc.Set.Cardinality(), b.Cardinality())
+			}
+			b.ForeachSetBit(0, func(i uint) bool {
+				if !doc.Set.Get(i) {
+					t.Fatalf("format %d: bit %d lost", f, i)
+				}
+				return false
+			})
+		}
+	}
+
+	doc := struct {
+		Set Formatted `json:"set"`
+	}{Formatted{fromList(3, 4), FormatIndexes}}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"set":[3,4]}` {
+		t.Errorf("json.Marshal = %s, want {\"set\":[3,4]}", data)
+	}
+	doc.Set = Formatted{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	checkBits(t, "Formatted", doc.Set.Set, 3, 4)
+	doc.Set = Formatted{}
+	if data, err := json.Marshal(doc); err != nil || string(data) != `{"set":null}` {
+		t.Errorf("json.Marshal(nil Set) = %s, %v, want {\"set\":null}", data, err)
+	}
+
+	b := fromList(7)
+	for _, s := range []string{`[18446744073709551614]`, `[1, 4294967296]`, `[4294967295]`, `"4294967295"`, `[67108864]`, `"1-18446744073709551614"`} {
+		if err := json.Unmarshal([]byte(s), b); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("json.Unmarshal(%s) = %v, want %v", s, err, ErrCorrupt)
+		}
+	}
+	checkBits(t, "UnmarshalJSON error", b, 7)
+	if err := json.Unmarshal([]byte(`[67108863]`), b); err != nil || !b.Get(67108863) {
+		t.Errorf("json.Unmarshal([67108863]) = %v", err)
+	}
+}
@@ -1,21 +1,44 @@
 package bloomfilter
 
 import (
+	"errors"
 	"math"
 
 	"github.com/liwnn/gopkg/bitset"
 )
 
+// ErrIncompatible 参数(位数, hash函数个数, hash函数, 种子)不一致
+var ErrIncompatible = errors.New("bloomfilter: incompatible parameters")
+
 // BloomFilter 布隆过滤器
 type BloomFilter struct {
 	bitSet    *bitset.BitSet // 位数组
+	numBits   uint64         // 位数组的位数
 	numHashes int            // hash函数个数
+	hasher    hasher         // hash函数和种子
+	capacity  uint64         // 预估元素个数
+	fpRate    float64        // 误判率
 }
 
 // New new
 // @param n - 预估元素个数
 // @param p - false positive(误判率)
-func New(n uint64, p float64) *BloomFilter {
+// @param opts - hash函数和种子等选项, 默认为MurmurHash3和种子0
+func New(n uint64, p float64, opts ...Option) *BloomFilter {
+	m, k := optimal(n, p)
+	bs := bitset.NewSize(uint(m))
+	return &BloomFilter{
+		bitSet:    bs,
+		numBits:   bs.Size(),
+		numHashes: k,
+		hasher:    newHasher(opts),
+		capacity:  n,
+		fpRate:    p,
+	}
+}
+
+// optimal 根据预估元素个数n和误判率p计算位数组的位数m和hash函数个数k
+func optimal(n uint64, p float64) (uint64, int) {
 	if p <= 0 || p >= 1 {
 		panic("The false positive rate must be in (0,1)")
 	}
@@ -23,14 +46,11 @@ func New(n uint64, p float64) *BloomFilter {
 	denom := 0.480453013918201                              // ln(2)^2
 	m := math.Ceil(-1 * (float64(n) * math.Log(p)) / denom) // 位数组的位数
 	k := math.Ceil(m / float64(n) * ln2)                    // hash函数个数
-	return &BloomFilter{
-		bitSet:    bitset.NewSize(uint(m)),
-		numHashes: int(k),
-	}
+	return uint64(m), int(k)
 }
 
 func (bf *BloomFilter) bloomHash(data []byte) (uint64, uint64) {
-	return MurmurHash3_x64_128(data, 0)
+	return bf.hasher.hash(data)
 }
 
 // Add 增加元素
@@ -38,7 +58,7 @@ func (bf *BloomFilter) Add(key []byte) {
 	h1, h2 := bf.bloomHash(key)
 	for i := 0; i < bf.numHashes; i++ {
 		// 双重散列法(Double Hashing): h(i,k) = (h1(k) + i*h2(k)) % TABLE_SIZE
-		h := (h1 + uint64(i)*h2) % bf.bitSet.Size()
+		h := (h1 + uint64(i)*h2) % bf.numBits
 		bf.bitSet.Set(uint(h))
 	}
 }
@@ -47,10 +67,33 @@ func (bf *BloomFilter) Add(key []byte) {
 func (bf *BloomFilter) MayContain(data []byte) bool {
 	h1, h2 := bf.bloomHash(data)
 	for i := 0; i < bf.numHashes; i++ {
-		h := (h1 + uint64(i)*h2) % bf.bitSet.Size()
+		h := (h1 + uint64(i)*h2) % bf.numBits
 		if !bf.bitSet.Get(uint(h)) {
 			return false
 		}
 	}
 	return true
 }
+
+// Compatible 是否与other的参数(位数, hash函数个数, hash函数, 种子)一致, 一致才能合并
+func (bf *BloomFilter) Compatible(other *BloomFilter) bool {
+	return bf.sameParams(other)
+}
+
+// Union 合并other的元素, 之后对两者中任一个加入过的元素MayContain都返回true
+func (bf *BloomFilter) Union(other *BloomFilter) error {
+	if !bf.Compatible(other) {
+		return ErrIncompatible
+	}
+	bf.bitSet.Or(other.bitSet)
+	return nil
+}
+
+// Intersect 与other取交集, 之后只对两者都加入过的元素MayContain一定返回true
+func (bf *BloomFilter) Intersect(other *BloomFilter) error {
+	if !bf.Compatible(other) {
+		return ErrIncompatible
+	}
+	bf.bitSet.And(other.bitSet)
+	return nil
+}
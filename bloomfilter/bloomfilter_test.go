@@ -1,6 +1,8 @@
 package bloomfilter
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -24,3 +26,62 @@ func TestBloomFilter(t *testing.T) {
 		t.Errorf("%s should be in the second time we look.", n3)
 	}
 }
+
+func TestUnion(t *testing.T) {
+	a := New(1000, 0.01)
+	b := New(1000, 0.01)
+	for i := 0; i < 100; i++ {
+		a.Add([]byte(fmt.Sprint("a", i)))
+		b.Add([]byte(fmt.Sprint("b", i)))
+		if i < 10 {
+			b.Add([]byte(fmt.Sprint("a", i)))
+		}
+	}
+	if !a.Compatible(b) {
+		t.Fatal("filters with the same parameters should be compatible")
+	}
+
+	u := New(1000, 0.01)
+	u.Union(a)
+	if err := u.Union(b); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if !u.MayContain([]byte(fmt.Sprint("a", i))) || !u.MayContain([]byte(fmt.Sprint("b", i))) {
+			t.Fatalf("Union lost %d", i)
+		}
+	}
+
+	if err := a.Intersect(b); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if !a.MayContain([]byte(fmt.Sprint("a", i))) {
+			t.Fatalf("Intersect lost a%d", i)
+		}
+	}
+	n := 0
+	for i := 50; i < 100; i++ {
+		if a.MayContain([]byte(fmt.Sprint("a", i))) {
+			n++
+		}
+	}
+	if n > 5 {
+		t.Errorf("Intersect keeps %d of 50 keys added to one filter only", n)
+	}
+}
+
+func TestIncompatible(t *testing.T) {
+	a := New(1000, 0.01)
+	for _, b := range []*BloomFilter{New(2000, 0.01), New(1000, 0.001)} {
+		if a.Compatible(b) {
+			t.Error("filters with different parameters should not be compatible")
+		}
+		if err := a.Union(b); !errors.Is(err, ErrIncompatible) {
+			t.Errorf("Union = %v, want %v", err, ErrIncompatible)
+		}
+		if err := a.Intersect(b); !errors.Is(err, ErrIncompatible) {
+			t.Errorf("Intersect = %v, want %v", err, ErrIncompatible)
+		}
+	}
+}
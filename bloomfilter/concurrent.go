@@ -0,0 +1,60 @@
+package bloomfilter
+
+import "sync/atomic"
+
+// ConcurrentBloomFilter 并发安全的布隆过滤器, 位数组预先分配, 用原子操作置位,
+// Add和MayContain可以在多个goroutine中同时调用, 不需要加锁.
+type ConcurrentBloomFilter struct {
+	words     []uint64 // 位数组, 只用原子操作读写
+	numBits   uint64   // 位数组的位数
+	numHashes int      // hash函数个数
+	hasher    hasher   // hash函数和种子
+}
+
+// NewConcurrent 创建并发安全的布隆过滤器, 参数与New相同
+// @param n - 预估元素个数
+// @param p - false positive(误判率)
+// @param opts - hash函数和种子等选项
+func NewConcurrent(n uint64, p float64, opts ...Option) *ConcurrentBloomFilter {
+	m, k := optimal(n, p)
+	words := (m + 63) / 64
+	return &ConcurrentBloomFilter{
+		words:     make([]uint64, words),
+		numBits:   words * 64,
+		numHashes: k,
+		hasher:    newHasher(opts),
+	}
+}
+
+// Add 增加元素
+func (cbf *ConcurrentBloomFilter) Add(key []byte) {
+	h1, h2 := cbf.hasher.hash(key)
+	for i := 0; i < cbf.numHashes; i++ {
+		h := (h1 + uint64(i)*h2) % cbf.numBits
+		cbf.set(h)
+	}
+}
+
+// set 用CAS循环原子地将第i位置1, 不维护计数, 以免所有goroutine争用同一个计数器
+func (cbf *ConcurrentBloomFilter) set(i uint64) {
+	addr := &cbf.words[i/64]
+	x := uint64(1) << (i % 64)
+	for {
+		old := atomic.LoadUint64(addr)
+		if old&x != 0 || atomic.CompareAndSwapUint64(addr, old, old|x) {
+			return
+		}
+	}
+}
+
+// MayContain 是否有存在可能
+func (cbf *ConcurrentBloomFilter) MayContain(key []byte) bool {
+	h1, h2 := cbf.hasher.hash(key)
+	for i := 0; i < cbf.numHashes; i++ {
+		h := (h1 + uint64(i)*h2) % cbf.numBits
+		if atomic.LoadUint64(&cbf.words[h/64])&(1<<(h%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
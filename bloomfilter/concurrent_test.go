@@ -0,0 +1,63 @@
+package bloomfilter
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentBloomFilter(t *testing.T) {
+	const workers = 8
+	const keys = 1000
+	cbf := NewConcurrent(workers*keys, 0.01)
+	bf := New(workers*keys, 0.01)
+	if cbf.numBits != bf.numBits || cbf.numHashes != bf.numHashes {
+		t.Error("NewConcurrent should be sized like New")
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < keys; i++ {
+				key := []byte(fmt.Sprint(w, "-", i))
+				cbf.Add(key)
+				if !cbf.MayContain(key) {
+					t.Errorf("%s should be in", key)
+				}
+				cbf.MayContain([]byte(fmt.Sprint(w+1, "-", i)))
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for w := 0; w < workers; w++ {
+		for i := 0; i < keys; i++ {
+			key := []byte(fmt.Sprint(w, "-", i))
+			bf.Add(key)
+			if !cbf.MayContain(key) {
+				t.Fatalf("%s should be in", key)
+			}
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		key := []byte(fmt.Sprint("other", i))
+		if cbf.MayContain(key) != bf.MayContain(key) {
+			t.Fatalf("MayContain(%s) differs from BloomFilter", key)
+		}
+	}
+}
+
+func BenchmarkConcurrentAdd(b *testing.B) {
+	cbf := NewConcurrent(1000000, 0.01)
+	b.RunParallel(func(pb *testing.PB) {
+		key := make([]byte, 8)
+		i := 0
+		for pb.Next() {
+			key[0], key[1], key[2] = byte(i), byte(i>>8), byte(i>>16)
+			cbf.Add(key)
+			i++
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package bloomfilter
+
+// CountingBloomFilter 计数布隆过滤器, 每一位换成一个饱和计数器, 因此支持Remove.
+// 计数器达到最大值后不再增减, 以免删除其它元素.
+type CountingBloomFilter struct {
+	counters   []byte // 计数器, 4位时每个字节存两个
+	numBits    uint64 // 计数器个数
+	numHashes  int    // hash函数个数
+	counterMax uint8  // 计数器最大值
+	hasher     hasher // hash函数和种子
+}
+
+// NewCounting 创建计数布隆过滤器, 计数器个数和hash函数个数与New(n, p)相同
+// @param n - 预估元素个数
+// @param p - false positive(误判率)
+// @param counterBits - 计数器位数, 4或8
+// @param opts - hash函数和种子等选项
+func NewCounting(n uint64, p float64, counterBits int, opts ...Option) *CountingBloomFilter {
+	m, k := optimal(n, p)
+	cbf := &CountingBloomFilter{
+		numBits:   m,
+		numHashes: k,
+		hasher:    newHasher(opts),
+	}
+	switch counterBits {
+	case 4:
+		cbf.counters = make([]byte, (m+1)/2)
+		cbf.counterMax = 0xf
+	case 8:
+		cbf.counters = make([]byte, m)
+		cbf.counterMax = 0xff
+	default:
+		panic("The counter bits must be 4 or 8")
+	}
+	return cbf
+}
+
+func (cbf *CountingBloomFilter) get(i uint64) uint8 {
+	if cbf.counterMax == 0xff {
+		return cbf.counters[i]
+	}
+	return cbf.counters[i>>1] >> (i & 1 * 4) & 0xf
+}
+
+func (cbf *CountingBloomFilter) set(i uint64, v uint8) {
+	if cbf.counterMax == 0xff {
+		cbf.counters[i] = v
+		return
+	}
+	shift := i & 1 * 4
+	cbf.counters[i>>1] = cbf.counters[i>>1]&^(0xf<<shift) | v<<shift
+}
+
+// Add 增加元素
+func (cbf *CountingBloomFilter) Add(key []byte) {
+	h1, h2 := cbf.hasher.hash(key)
+	for i := 0; i < cbf.numHashes; i++ {
+		h := (h1 + uint64(i)*h2) % cbf.numBits
+		if c := cbf.get(h); c < cbf.counterMax {
+			cbf.set(h, c+1)
+		}
+	}
+}
+
+// Remove 删除元素, 元素不可能存在时返回false且不做修改.
+// 只能删除加入过的元素, 否则会误删其它元素.
+func (cbf *CountingBloomFilter) Remove(key []byte) bool {
+	if !cbf.MayContain(key) {
+		return false
+	}
+	h1, h2 := cbf.hasher.hash(key)
+	for i := 0; i < cbf.numHashes; i++ {
+		h := (h1 + uint64(i)*h2) % cbf.numBits
+		if c := cbf.get(h); c > 0 && c < cbf.counterMax {
+			cbf.set(h, c-1)
+		}
+	}
+	return true
+}
+
+// MayContain 是否有存在可能
+func (cbf *CountingBloomFilter) MayContain(key []byte) bool {
+	return cbf.Count(key) > 0
+}
+
+// Count 元素加入次数的估计值(不小于实际次数, 计数器饱和时除外)
+func (cbf *CountingBloomFilter) Count(key []byte) uint {
+	h1, h2 := cbf.hasher.hash(key)
+	min := cbf.counterMax
+	for i := 0; i < cbf.numHashes && min > 0; i++ {
+		h := (h1 + uint64(i)*h2) % cbf.numBits
+		if c := cbf.get(h); c < min {
+			min = c
+		}
+	}
+	return uint(min)
+}
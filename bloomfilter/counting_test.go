@@ -0,0 +1,72 @@
+package bloomfilter
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCountingBloomFilter(t *testing.T) {
+	for _, counterBits := range []int{4, 8} {
+		cbf := NewCounting(1000, 0.01, counterBits)
+		for i := 0; i < 500; i++ {
+			cbf.Add([]byte(fmt.Sprint("key", i)))
+		}
+		cbf.Add([]byte("key0"))
+		if c := cbf.Count([]byte("key0")); c < 2 {
+			t.Errorf("%d bits: Count(key0) = %d, want at least 2", counterBits, c)
+		}
+		for i := 0; i < 250; i++ {
+			if !cbf.Remove([]byte(fmt.Sprint("key", i))) {
+				t.Fatalf("%d bits: Remove(key%d) = false", counterBits, i)
+			}
+		}
+		if !cbf.MayContain([]byte("key0")) {
+			t.Errorf("%d bits: key0 was added twice and removed once", counterBits)
+		}
+		for i := 250; i < 500; i++ {
+			if !cbf.MayContain([]byte(fmt.Sprint("key", i))) {
+				t.Fatalf("%d bits: key%d should be in", counterBits, i)
+			}
+		}
+		n := 0
+		for i := 1; i < 250; i++ {
+			if cbf.MayContain([]byte(fmt.Sprint("key", i))) {
+				n++
+			}
+		}
+		if n > 10 {
+			t.Errorf("%d bits: %d of 249 removed keys still in", counterBits, n)
+		}
+		if cbf.Remove([]byte("Peek")) {
+			t.Errorf("%d bits: Remove of a key never added should fail", counterBits)
+		}
+	}
+}
+
+func TestCountingSaturate(t *testing.T) {
+	cbf := NewCounting(100, 0.1, 4)
+	for i := 0; i < 20; i++ {
+		cbf.Add([]byte("Hurst"))
+	}
+	if c := cbf.Count([]byte("Hurst")); c != 15 {
+		t.Errorf("Count = %d, want 15", c)
+	}
+	for i := 0; i < 20; i++ {
+		cbf.Remove([]byte("Hurst"))
+	}
+	if !cbf.MayContain([]byte("Hurst")) {
+		t.Error("saturated counters should not be decremented")
+	}
+	if m, k := optimal(100, 0.1); cbf.numBits != m || cbf.numHashes != New(100, 0.1).numHashes || cbf.numHashes != k {
+		t.Error("NewCounting should be sized like New")
+	}
+}
+
+func TestNewCountingPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewCounting(100, 0.1, 2) should panic")
+		}
+	}()
+	NewCounting(100, 0.1, 2)
+}
@@ -0,0 +1,176 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+
+	"github.com/liwnn/gopkg/bitset"
+)
+
+// 序列化格式(小端序):
+//
+//	magic "BLMF" | version u8 | hash u8 | 保留 u16 | seed u32 | numHashes u32 |
+//	numBits u64 | capacity u64 | fpRate f64 | 位数组(bitset二进制格式) | crc32 u32
+//
+// crc32(IEEE)覆盖它之前的所有字节.
+const (
+	magic      = "BLMF"
+	version    = 1
+	headerSize = 40
+
+	// 反序列化时接受的最大位数和hash函数个数, 以免恶意数据耗尽内存或CPU.
+	// 位数上限为2^40(128GiB), hash函数超过255个时误判率已低于1e-76.
+	maxNumBits   = 1 << 40
+	maxNumHashes = 255
+)
+
+var (
+	// ErrInvalidFormat 数据不是序列化的布隆过滤器
+	ErrInvalidFormat = errors.New("bloomfilter: invalid format")
+	// ErrTruncated 数据不完整
+	ErrTruncated = errors.New("bloomfilter: truncated data")
+	// ErrChecksum 校验和不匹配
+	ErrChecksum = errors.New("bloomfilter: checksum mismatch")
+)
+
+// MarshalBinary 实现encoding.BinaryMarshaler
+func (bf *BloomFilter) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := bf.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary 实现encoding.BinaryUnmarshaler
+// 如果bf已经通过New创建, 数据的参数必须与bf一致, 否则返回ErrIncompatible.
+// 使用WithHashFunc的数据只能反序列化到用同一函数创建的bf.
+func (bf *BloomFilter) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	if _, err := bf.ReadFrom(r); err != nil {
+		return err
+	}
+	if r.Len() != 0 {
+		return ErrInvalidFormat
+	}
+	return nil
+}
+
+// WriteTo 实现io.WriterTo
+func (bf *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	var header [headerSize]byte
+	copy(header[:], magic)
+	header[4] = version
+	header[5] = uint8(bf.hasher.id)
+	binary.LittleEndian.PutUint32(header[8:], bf.hasher.seed)
+	binary.LittleEndian.PutUint32(header[12:], uint32(bf.numHashes))
+	binary.LittleEndian.PutUint64(header[16:], bf.numBits)
+	binary.LittleEndian.PutUint64(header[24:], bf.capacity)
+	binary.LittleEndian.PutUint64(header[32:], math.Float64bits(bf.fpRate))
+
+	crc := crc32.NewIEEE()
+	mw := io.MultiWriter(w, crc)
+	n, err := mw.Write(header[:])
+	written := int64(n)
+	if err != nil {
+		return written, err
+	}
+	m, err := bf.bitSet.WriteTo(mw)
+	written += m
+	if err != nil {
+		return written, err
+	}
+	var sum [4]byte
+	binary.LittleEndian.PutUint32(sum[:], crc.Sum32())
+	n, err = w.Write(sum[:])
+	return written + int64(n), err
+}
+
+// ReadFrom 实现io.ReaderFrom
+// 如果bf已经通过New创建, 数据的参数必须与bf一致, 否则返回ErrIncompatible.
+func (bf *BloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	crc := crc32.NewIEEE()
+	tr := io.TeeReader(r, crc)
+
+	var header [headerSize]byte
+	n, err := io.ReadFull(tr, header[:])
+	read := int64(n)
+	if err != nil {
+		return read, readError(err)
+	}
+	if string(header[:4]) != magic || header[4] != version {
+		return read, ErrInvalidFormat
+	}
+	d := BloomFilter{
+		hasher: hasher{
+			id:   Hash(header[5]),
+			seed: binary.LittleEndian.Uint32(header[8:]),
+		},
+		numHashes: int(binary.LittleEndian.Uint32(header[12:])),
+		numBits:   binary.LittleEndian.Uint64(header[16:]),
+		capacity:  binary.LittleEndian.Uint64(header[24:]),
+		fpRate:    math.Float64frombits(binary.LittleEndian.Uint64(header[32:])),
+		bitSet:    bitset.New(),
+	}
+	if d.numBits == 0 || d.numBits > maxNumBits || d.numBits > uint64(^uint(0)) ||
+		d.numHashes <= 0 || d.numHashes > maxNumHashes || !validHash(header[5]) {
+		return read, ErrInvalidFormat
+	}
+	if bf.bitSet != nil && !bf.sameParams(&d) {
+		return read, ErrIncompatible
+	}
+	if d.hasher.id == HashCustom {
+		// 自定义hash函数无法序列化, 只能使用bf的
+		if bf.bitSet == nil {
+			return read, ErrIncompatible
+		}
+		d.hasher.fn = bf.hasher.fn
+	}
+
+	m, err := d.bitSet.ReadFrom(tr)
+	read += m
+	if err != nil {
+		switch {
+		case errors.Is(err, bitset.ErrTruncated):
+			return read, ErrTruncated
+		case errors.Is(err, bitset.ErrCorrupt), errors.Is(err, bitset.ErrUnsupportedVersion):
+			return read, ErrInvalidFormat
+		}
+		return read, err
+	}
+	if uint64(d.bitSet.Length()) > d.numBits {
+		return read, ErrInvalidFormat
+	}
+
+	want := crc.Sum32()
+	var sum [4]byte
+	n, err = io.ReadFull(r, sum[:])
+	read += int64(n)
+	if err != nil {
+		return read, readError(err)
+	}
+	if binary.LittleEndian.Uint32(sum[:]) != want {
+		return read, ErrChecksum
+	}
+	*bf = d
+	return read, nil
+}
+
+// sameParams 位数, hash函数个数, hash函数和种子是否一致
+func (bf *BloomFilter) sameParams(other *BloomFilter) bool {
+	return bf.numBits == other.numBits &&
+		bf.numHashes == other.numHashes &&
+		bf.hasher.id == other.hasher.id &&
+		bf.hasher.seed == other.hasher.seed
+}
+
+func readError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrTruncated
+	}
+	return err
+}
@@ -0,0 +1,178 @@
+package bloomfilter
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"testing"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*BloomFilter)(nil)
+	_ encoding.BinaryUnmarshaler = (*BloomFilter)(nil)
+	_ io.WriterTo                = (*BloomFilter)(nil)
+	_ io.ReaderFrom              = (*BloomFilter)(nil)
+)
+
+func TestMarshalBinary(t *testing.T) {
+	bf := New(1000, 0.01)
+	for i := 0; i < 500; i++ {
+		bf.Add([]byte(fmt.Sprint("key", i)))
+	}
+	data, err := bf.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var d BloomFilter
+	if err := d.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 500; i++ {
+		if !d.MayContain([]byte(fmt.Sprint("key", i))) {
+			t.Fatalf("key%d should be in", i)
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		key := []byte(fmt.Sprint("other", i))
+		if d.MayContain(key) != bf.MayContain(key) {
+			t.Fatalf("MayContain(%s) differs", key)
+		}
+	}
+
+	e := New(1000, 0.01)
+	if err := e.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !e.MayContain([]byte("key1")) {
+		t.Error("key1 should be in")
+	}
+	if err := New(1000, 0.001).UnmarshalBinary(data); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("UnmarshalBinary = %v, want %v", err, ErrIncompatible)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	bf := New(100, 0.1)
+	bf.Add([]byte("Hurst"))
+	var buf bytes.Buffer
+	n, err := bf.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo = %d, want %d", n, buf.Len())
+	}
+	buf.WriteString("next")
+
+	var d BloomFilter
+	m, err := d.ReadFrom(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m != n || buf.String() != "next" {
+		t.Errorf("ReadFrom = %d, want %d", m, n)
+	}
+	if !d.MayContain([]byte("Hurst")) || d.MayContain([]byte("Peek")) {
+		t.Error("MayContain")
+	}
+}
+
+func TestUnmarshalBinaryError(t *testing.T) {
+	bf := New(100, 0.1)
+	bf.Add([]byte("Hurst"))
+	data, _ := bf.MarshalBinary()
+
+	corrupt := func(i int) []byte {
+		c := append([]byte(nil), data...)
+		c[i] ^= 0xff
+		return c
+	}
+	tests := []struct {
+		data []byte
+		err  error
+	}{
+		{nil, ErrTruncated},
+		{data[:10], ErrTruncated},
+		{data[:headerSize+5], ErrTruncated},
+		{data[:len(data)-1], ErrTruncated},
+		{corrupt(0), ErrInvalidFormat},
+		{corrupt(4), ErrInvalidFormat},
+		{corrupt(5), ErrInvalidFormat},
+		{corrupt(8), ErrChecksum},
+		{corrupt(headerSize + 10), ErrChecksum},
+		{corrupt(len(data) - 1), ErrChecksum},
+		{append(data[:len(data):len(data)], 0), ErrInvalidFormat},
+	}
+	for i, tt := range tests {
+		var d BloomFilter
+		if err := d.UnmarshalBinary(tt.data); !errors.Is(err, tt.err) {
+			t.Errorf("%d: UnmarshalBinary = %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+func TestUnmarshalBinaryHostile(t *testing.T) {
+	bf := New(100, 0.1)
+	bf.Add([]byte("Hurst"))
+	data, _ := bf.MarshalBinary()
+
+	// craft 修改头部后重新计算校验和
+	craft := func(f func(header []byte)) []byte {
+		c := append([]byte(nil), data...)
+		f(c)
+		n := len(c) - 4
+		binary.LittleEndian.PutUint32(c[n:], crc32.ChecksumIEEE(c[:n]))
+		return c
+	}
+	tests := [][]byte{
+		craft(func(h []byte) { binary.LittleEndian.PutUint64(h[16:], 1<<62) }),
+		craft(func(h []byte) { binary.LittleEndian.PutUint64(h[16:], maxNumBits+1) }),
+		craft(func(h []byte) { binary.LittleEndian.PutUint32(h[12:], 0xffffffff) }),
+		craft(func(h []byte) { binary.LittleEndian.PutUint32(h[12:], maxNumHashes+1) }),
+		craft(func(h []byte) { binary.LittleEndian.PutUint32(h[12:], 0) }),
+	}
+	for i, data := range tests {
+		var d BloomFilter
+		if err := d.UnmarshalBinary(data); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("%d: UnmarshalBinary = %v, want %v", i, err, ErrInvalidFormat)
+		}
+	}
+
+	// 位数很大但合法时不会预先分配位数组
+	maxBits := uint64(maxNumBits)
+	if uint64(^uint(0)) < maxBits {
+		maxBits = uint64(^uint(0)) // 32位平台上限为uint的最大值
+	}
+	big := craft(func(h []byte) { binary.LittleEndian.PutUint64(h[16:], maxBits) })
+	var d BloomFilter
+	if err := d.UnmarshalBinary(big); err != nil {
+		t.Fatal(err)
+	}
+	if d.bitSet.Size() > 1<<20 {
+		t.Errorf("Size = %d after UnmarshalBinary", d.bitSet.Size())
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
+
+func TestReadFromError(t *testing.T) {
+	bf := New(100, 0.1)
+	bf.Add([]byte("Hurst"))
+	data, _ := bf.MarshalBinary()
+
+	errRead := errors.New("read failed")
+	for _, n := range []int{0, 10, headerSize, headerSize + 5, len(data) - 1} {
+		var d BloomFilter
+		r := io.MultiReader(bytes.NewReader(data[:n]), errReader{errRead})
+		if _, err := d.ReadFrom(r); err != errRead {
+			t.Errorf("%d: ReadFrom = %v, want %v", n, err, errRead)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package bloomfilter
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"hash/fnv"
+	"math/bits"
+)
+
+// Hash hash函数族, 编号会被序列化
+type Hash uint8
+
+const (
+	// HashMurmur3 MurmurHash3_x64_128, 默认
+	HashMurmur3 Hash = 1
+	// HashXXHash64 xxHash64, 用seed和seed+1各计算一次
+	HashXXHash64 Hash = 2
+	// HashFNV FNV-1a 128位, seed作为前缀
+	HashFNV Hash = 3
+	// HashCustom 通过WithHashFunc指定的函数
+	HashCustom Hash = 0xff
+)
+
+// HashFunc 自定义hash函数, 返回双重散列使用的两个hash值
+type HashFunc func(data []byte, seed uint32) (uint64, uint64)
+
+// Option 创建过滤器的选项
+type Option func(*hasher)
+
+// WithHash 使用hash函数族h
+func WithHash(h Hash) Option {
+	if !validHash(uint8(h)) || h == HashCustom {
+		panic("Unknown hash")
+	}
+	return func(hs *hasher) {
+		hs.id = h
+		hs.fn = nil
+	}
+}
+
+// WithHashFunc 使用自定义hash函数. 序列化数据只能反序列化到用同一函数创建的过滤器.
+func WithHashFunc(fn HashFunc) Option {
+	return func(hs *hasher) {
+		hs.id = HashCustom
+		hs.fn = fn
+	}
+}
+
+// WithSeed 使用指定的hash种子, 默认为0
+func WithSeed(seed uint32) Option {
+	return func(hs *hasher) {
+		hs.seed = seed
+	}
+}
+
+// WithRandomSeed 使用随机的hash种子, 使攻击者无法构造冲突的元素
+func WithRandomSeed() Option {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return WithSeed(binary.LittleEndian.Uint32(b[:]))
+}
+
+// hasher 过滤器使用的hash函数和种子
+type hasher struct {
+	id   Hash
+	seed uint32
+	fn   HashFunc
+}
+
+func newHasher(opts []Option) hasher {
+	hs := hasher{id: HashMurmur3}
+	for _, opt := range opts {
+		opt(&hs)
+	}
+	return hs
+}
+
+func validHash(id uint8) bool {
+	switch Hash(id) {
+	case HashMurmur3, HashXXHash64, HashFNV, HashCustom:
+		return true
+	}
+	return false
+}
+
+// hash 返回双重散列使用的两个hash值
+func (hs *hasher) hash(data []byte) (uint64, uint64) {
+	switch hs.id {
+	case HashXXHash64:
+		return XXHash64(data, uint64(hs.seed)), XXHash64(data, uint64(hs.seed)+1)
+	case HashFNV:
+		h := fnv.New128a()
+		var seed [4]byte
+		binary.LittleEndian.PutUint32(seed[:], hs.seed)
+		h.Write(seed[:])
+		h.Write(data)
+		var sum [16]byte
+		h.Sum(sum[:0])
+		return binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:])
+	case HashCustom:
+		return hs.fn(data, hs.seed)
+	}
+	return MurmurHash3_x64_128(data, hs.seed)
+}
+
+const (
+	xxPrime1 uint64 = 11400714785074694791
+	xxPrime2 uint64 = 14029467366897019727
+	xxPrime3 uint64 = 1609587929392839161
+	xxPrime4 uint64 = 9650029242287828579
+	xxPrime5 uint64 = 2870177450012600261
+)
+
+func xxRound(acc, input uint64) uint64 {
+	acc += input * xxPrime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxPrime1
+}
+
+func xxMergeRound(acc, val uint64) uint64 {
+	acc ^= xxRound(0, val)
+	return acc*xxPrime1 + xxPrime4
+}
+
+// XXHash64 xxHash的64位版本
+func XXHash64(data []byte, seed uint64) uint64 {
+	n := len(data)
+	var h uint64
+	if n >= 32 {
+		v1 := seed + xxPrime1 + xxPrime2
+		v2 := seed + xxPrime2
+		v3 := seed
+		v4 := seed - xxPrime1
+		for len(data) >= 32 {
+			v1 = xxRound(v1, binary.LittleEndian.Uint64(data[0:]))
+			v2 = xxRound(v2, binary.LittleEndian.Uint64(data[8:]))
+			v3 = xxRound(v3, binary.LittleEndian.Uint64(data[16:]))
+			v4 = xxRound(v4, binary.LittleEndian.Uint64(data[24:]))
+			data = data[32:]
+		}
+		h = bits.RotateLeft64(v1, 1) + bits.RotateLeft64(v2, 7) +
+			bits.RotateLeft64(v3, 12) + bits.RotateLeft64(v4, 18)
+		h = xxMergeRound(h, v1)
+		h = xxMergeRound(h, v2)
+		h = xxMergeRound(h, v3)
+		h = xxMergeRound(h, v4)
+	} else {
+		h = seed + xxPrime5
+	}
+	h += uint64(n)
+
+	for ; len(data) >= 8; data = data[8:] {
+		h ^= xxRound(0, binary.LittleEndian.Uint64(data))
+		h = bits.RotateLeft64(h, 27)*xxPrime1 + xxPrime4
+	}
+	if len(data) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(data)) * xxPrime1
+		h = bits.RotateLeft64(h, 23)*xxPrime2 + xxPrime3
+		data = data[4:]
+	}
+	for _, c := range data {
+		h ^= uint64(c) * xxPrime5
+		h = bits.RotateLeft64(h, 11) * xxPrime1
+	}
+
+	h ^= h >> 33
+	h *= xxPrime2
+	h ^= h >> 29
+	h *= xxPrime3
+	h ^= h >> 32
+	return h
+}
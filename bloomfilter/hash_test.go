@@ -0,0 +1,111 @@
+package bloomfilter
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"testing"
+)
+
+func TestXXHash64(t *testing.T) {
+	tests := []struct {
+		data string
+		seed uint64
+		want uint64
+	}{
+		{"", 0, 0xef46db3751d8e999},
+		{"a", 0, 0xd24ec4f1a98c6e5b},
+		{"abc", 0, 0x44bc2cf5ad770999},
+		{"Nobody inspects the spammish repetition", 0, 0xfbcea83c8a378bf1},
+	}
+	for _, tt := range tests {
+		if h := XXHash64([]byte(tt.data), tt.seed); h != tt.want {
+			t.Errorf("XXHash64(%q, %d) = %#x, want %#x", tt.data, tt.seed, h, tt.want)
+		}
+	}
+	if XXHash64([]byte("abc"), 1) == XXHash64([]byte("abc"), 0) {
+		t.Error("XXHash64 should depend on the seed")
+	}
+}
+
+func crcHash(data []byte, seed uint32) (uint64, uint64) {
+	table := crc64.MakeTable(crc64.ECMA)
+	h := crc64.Update(uint64(seed), table, data)
+	return h, crc64.Update(h, table, data)
+}
+
+func TestHashOptions(t *testing.T) {
+	options := [][]Option{
+		nil,
+		{WithHash(HashXXHash64)},
+		{WithHash(HashFNV), WithSeed(7)},
+		{WithHashFunc(crcHash)},
+		{WithRandomSeed()},
+	}
+	for i, opts := range options {
+		bf := New(1000, 0.01, opts...)
+		for j := 0; j < 500; j++ {
+			bf.Add([]byte(fmt.Sprint("key", j)))
+		}
+		fp := 0
+		for j := 0; j < 1000; j++ {
+			if !bf.MayContain([]byte(fmt.Sprint("key", j%500))) {
+				t.Fatalf("%d: key%d should be in", i, j%500)
+			}
+			if bf.MayContain([]byte(fmt.Sprint("other", j))) {
+				fp++
+			}
+		}
+		if fp > 30 {
+			t.Errorf("%d: %d false positives in 1000", i, fp)
+		}
+	}
+
+	a := New(1000, 0.01, WithSeed(1))
+	b := New(1000, 0.01, WithSeed(2))
+	if a.Compatible(b) || a.Compatible(New(1000, 0.01, WithSeed(1), WithHash(HashFNV))) {
+		t.Error("filters with different hashes should not be compatible")
+	}
+	a.Add([]byte("Hurst"))
+	b.Add([]byte("Hurst"))
+	if a.bitSet.Equal(b.bitSet) {
+		t.Error("different seeds should set different bits")
+	}
+}
+
+func TestHashEncoding(t *testing.T) {
+	bf := New(100, 0.01, WithHash(HashXXHash64), WithSeed(42))
+	bf.Add([]byte("Hurst"))
+	data, _ := bf.MarshalBinary()
+	var d BloomFilter
+	if err := d.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if d.hasher.id != HashXXHash64 || d.hasher.seed != 42 || !d.MayContain([]byte("Hurst")) {
+		t.Errorf("hash = %d, seed = %d", d.hasher.id, d.hasher.seed)
+	}
+
+	custom := New(100, 0.01, WithHashFunc(crcHash))
+	custom.Add([]byte("Hurst"))
+	data, _ = custom.MarshalBinary()
+	var e BloomFilter
+	if err := e.UnmarshalBinary(data); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("UnmarshalBinary = %v, want %v", err, ErrIncompatible)
+	}
+	f := New(100, 0.01, WithHashFunc(crcHash))
+	if err := f.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !f.MayContain([]byte("Hurst")) {
+		t.Error("Hurst should be in")
+	}
+}
+
+func TestWithHashPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("WithHash(0) should panic")
+		}
+	}()
+	WithHash(0)
+}
@@ -0,0 +1,91 @@
+package bloomfilter
+
+import "math"
+
+const (
+	scalableGrowth = 2   // 每层容量是上一层的倍数
+	scalableRatio  = 0.8 // 每层误判率是上一层的倍数
+)
+
+// ScalableBloomFilter 可扩展布隆过滤器, 元素个数超过容量时增加一层容量翻倍,
+// 误判率按比例收紧的过滤器, 总误判率不超过目标误判率.
+type ScalableBloomFilter struct {
+	layers []*BloomFilter
+	counts []uint64 // 每层加入的元素个数
+	fpRate float64  // 目标误判率
+	opts   []Option // 每层使用的选项
+}
+
+// NewScalable 创建可扩展布隆过滤器
+// @param n - 第一层的预估元素个数
+// @param p - false positive(目标误判率)
+// @param opts - hash函数和种子等选项, 所有层相同
+func NewScalable(n uint64, p float64, opts ...Option) *ScalableBloomFilter {
+	if p <= 0 || p >= 1 {
+		panic("The false positive rate must be in (0,1)")
+	}
+	if n == 0 {
+		n = 1
+	}
+	sbf := &ScalableBloomFilter{fpRate: p, opts: opts}
+	// 各层误判率 p(1-r), p(1-r)r, p(1-r)r^2... 之和不超过p
+	sbf.addLayer(n, p*(1-scalableRatio))
+	return sbf
+}
+
+func (sbf *ScalableBloomFilter) addLayer(n uint64, p float64) {
+	sbf.layers = append(sbf.layers, New(n, p, sbf.opts...))
+	sbf.counts = append(sbf.counts, 0)
+}
+
+// Add 增加元素, 可能已存在的元素不会重复计数
+func (sbf *ScalableBloomFilter) Add(key []byte) {
+	if sbf.MayContain(key) {
+		return
+	}
+	last := len(sbf.layers) - 1
+	if l := sbf.layers[last]; sbf.counts[last] >= l.capacity {
+		sbf.addLayer(l.capacity*scalableGrowth, l.fpRate*scalableRatio)
+		last++
+	}
+	sbf.layers[last].Add(key)
+	sbf.counts[last]++
+}
+
+// MayContain 是否有存在可能
+func (sbf *ScalableBloomFilter) MayContain(key []byte) bool {
+	for i := len(sbf.layers) - 1; i >= 0; i-- {
+		if sbf.layers[i].MayContain(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Layers 层数
+func (sbf *ScalableBloomFilter) Layers() int {
+	return len(sbf.layers)
+}
+
+// Count 加入的元素个数
+func (sbf *ScalableBloomFilter) Count() uint64 {
+	var n uint64
+	for _, c := range sbf.counts {
+		n += c
+	}
+	return n
+}
+
+// FalsePositiveRate 按各层当前元素个数估计的误判率
+func (sbf *ScalableBloomFilter) FalsePositiveRate() float64 {
+	q := 1.0 // 各层都不误判的概率
+	for i, l := range sbf.layers {
+		q *= 1 - expectedFPRate(l.numBits, l.numHashes, sbf.counts[i])
+	}
+	return 1 - q
+}
+
+// expectedFPRate 位数为m, hash函数个数为k的过滤器加入n个元素后的误判率 (1-e^(-kn/m))^k
+func expectedFPRate(m uint64, k int, n uint64) float64 {
+	return math.Pow(1-math.Exp(-float64(k)*float64(n)/float64(m)), float64(k))
+}
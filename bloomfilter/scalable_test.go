@@ -0,0 +1,48 @@
+package bloomfilter
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScalableBloomFilter(t *testing.T) {
+	const p = 0.01
+	sbf := NewScalable(100, p)
+	if sbf.Layers() != 1 || sbf.FalsePositiveRate() != 0 {
+		t.Errorf("Layers = %d, FalsePositiveRate = %v", sbf.Layers(), sbf.FalsePositiveRate())
+	}
+	for i := 0; i < 5000; i++ {
+		sbf.Add([]byte(fmt.Sprint("key", i)))
+	}
+	if sbf.Layers() < 5 {
+		t.Errorf("Layers = %d, want at least 5", sbf.Layers())
+	}
+	if c := sbf.Count(); c > 5000 || c < 4900 {
+		t.Errorf("Count = %d, want about 5000", c)
+	}
+	for i := 0; i < 5000; i++ {
+		if !sbf.MayContain([]byte(fmt.Sprint("key", i))) {
+			t.Fatalf("key%d should be in", i)
+		}
+	}
+	if r := sbf.FalsePositiveRate(); r <= 0 || r > p {
+		t.Errorf("FalsePositiveRate = %v, want in (0, %v]", r, p)
+	}
+
+	fp := 0
+	const tries = 20000
+	for i := 0; i < tries; i++ {
+		if sbf.MayContain([]byte(fmt.Sprint("other", i))) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / tries; rate > 2*p {
+		t.Errorf("measured false positive rate %v, want about %v", rate, p)
+	}
+
+	layers := sbf.Layers()
+	sbf.Add([]byte("key1"))
+	if sbf.Layers() != layers || sbf.Count() > 5000 {
+		t.Error("adding a present key should not count")
+	}
+}
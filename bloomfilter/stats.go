@@ -0,0 +1,50 @@
+package bloomfilter
+
+import "math"
+
+// Capacity 预估元素个数
+func (bf *BloomFilter) Capacity() uint64 {
+	return bf.capacity
+}
+
+// TargetFalsePositiveRate 创建时指定的误判率
+func (bf *BloomFilter) TargetFalsePositiveRate() float64 {
+	return bf.fpRate
+}
+
+// NumBits 位数组的位数
+func (bf *BloomFilter) NumBits() uint64 {
+	return bf.numBits
+}
+
+// NumHashes hash函数个数
+func (bf *BloomFilter) NumHashes() int {
+	return bf.numHashes
+}
+
+// PopCount 值为1的位数
+func (bf *BloomFilter) PopCount() uint64 {
+	return uint64(bf.bitSet.Cardinality())
+}
+
+// FillRatio 值为1的位所占比例
+func (bf *BloomFilter) FillRatio() float64 {
+	return float64(bf.PopCount()) / float64(bf.numBits)
+}
+
+// EstimatedCount 按Swamidass–Baldi公式 n = -(m/k)·ln(1-X/m) 估计加入的元素个数,
+// m为位数, k为hash函数个数, X为值为1的位数. 位数组全满时无法估计, 返回math.MaxUint64.
+func (bf *BloomFilter) EstimatedCount() uint64 {
+	x := bf.PopCount()
+	if x >= bf.numBits {
+		return math.MaxUint64
+	}
+	m := float64(bf.numBits)
+	n := -m / float64(bf.numHashes) * math.Log1p(-float64(x)/m)
+	return uint64(math.Round(n))
+}
+
+// FalsePositiveRate 按当前值为1的位所占比例估计的误判率 (X/m)^k
+func (bf *BloomFilter) FalsePositiveRate() float64 {
+	return math.Pow(bf.FillRatio(), float64(bf.numHashes))
+}
@@ -0,0 +1,40 @@
+package bloomfilter
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	bf := New(1000, 0.01)
+	m, k := optimal(1000, 0.01)
+	if bf.Capacity() != 1000 || bf.TargetFalsePositiveRate() != 0.01 || bf.NumHashes() != k || bf.NumBits() < m {
+		t.Fatalf("Capacity = %d, TargetFalsePositiveRate = %v, NumBits = %d, NumHashes = %d",
+			bf.Capacity(), bf.TargetFalsePositiveRate(), bf.NumBits(), bf.NumHashes())
+	}
+	if bf.PopCount() != 0 || bf.FillRatio() != 0 || bf.EstimatedCount() != 0 || bf.FalsePositiveRate() != 0 {
+		t.Errorf("empty filter: PopCount = %d, EstimatedCount = %d", bf.PopCount(), bf.EstimatedCount())
+	}
+
+	for i := 0; i < 1000; i++ {
+		bf.Add([]byte(fmt.Sprint("key", i)))
+	}
+	if n := bf.EstimatedCount(); n < 950 || n > 1050 {
+		t.Errorf("EstimatedCount = %d, want about 1000", n)
+	}
+	if r := bf.FillRatio(); math.Abs(r-0.5) > 0.05 {
+		t.Errorf("FillRatio = %v, want about 0.5", r)
+	}
+	if p := bf.FalsePositiveRate(); p < 0.005 || p > 0.02 {
+		t.Errorf("FalsePositiveRate = %v, want about 0.01", p)
+	}
+	if want := float64(bf.PopCount()) / float64(bf.NumBits()); bf.FillRatio() != want {
+		t.Errorf("FillRatio = %v, want %v", bf.FillRatio(), want)
+	}
+
+	bf.bitSet.SetRange(0, uint(bf.NumBits()))
+	if bf.EstimatedCount() != math.MaxUint64 || bf.FalsePositiveRate() != 1 {
+		t.Errorf("full filter: EstimatedCount = %d, FalsePositiveRate = %v", bf.EstimatedCount(), bf.FalsePositiveRate())
+	}
+}